@@ -0,0 +1,73 @@
+package nksdl
+
+import (
+	"fmt"
+)
+
+// CloneBindings returns a copy of bindings, e.g. to customize a copy of
+// DefaultBindings without modifying it.
+func CloneBindings(bindings map[KeyInput]KeyAction) map[KeyInput]KeyAction {
+	clone := make(map[KeyInput]KeyAction, len(bindings))
+	for input, action := range bindings {
+		clone[input] = action
+	}
+	return clone
+}
+
+// BindingsBuilder builds a map of bindings for NewDriver or NewEventHandler,
+// starting from a base set such as DefaultBindings. Unlike NewEventHandler,
+// which panics on conflicting bindings, BindingsBuilder reports conflicts as
+// errors as soon as they are added. For example:
+//
+//	b := nksdl.NewBindingsBuilder(nksdl.DefaultBindings)
+//	b.Remove(nksdl.KeyInput{Code: sdl.K_a, Mod: sdl.KMOD_CTRL})
+//	if err := b.Add(nksdl.KeyInput{Code: sdl.K_a, Mod: sdl.KMOD_CTRL}, nksdl.KeyAction{Key1: nk.KeyTextSelectAll}); err != nil {
+//		return err
+//	}
+//	driver := nksdl.NewDriver(sdlDriver, nkDriver, b.Bindings(), nil)
+type BindingsBuilder struct {
+	bindings map[KeyInput]KeyAction
+}
+
+// NewBindingsBuilder creates a BindingsBuilder starting from a copy of base,
+// which may be nil to start from no bindings.
+func NewBindingsBuilder(base map[KeyInput]KeyAction) *BindingsBuilder {
+	return &BindingsBuilder{bindings: CloneBindings(base)}
+}
+
+// Add adds a binding. It fails if input is already bound to a different
+// action, in which case it must be removed first, or if the binding conflicts
+// with another one once generic modifiers are expanded (see NewEventHandler),
+// e.g. a binding for Ctrl+X with one for LCtrl+X to a different action.
+func (b *BindingsBuilder) Add(input KeyInput, action KeyAction) error {
+	return b.Merge(map[KeyInput]KeyAction{input: action})
+}
+
+// Remove removes the binding for input, if any. Bindings for generic modifiers
+// are distinct from those for their variants, so removing Ctrl+X does not
+// remove LCtrl+X, nor the reverse.
+func (b *BindingsBuilder) Remove(input KeyInput) {
+	delete(b.bindings, input)
+}
+
+// Merge adds all of the given bindings, as with Add. If any of them fails to
+// be added, none are.
+func (b *BindingsBuilder) Merge(bindings map[KeyInput]KeyAction) error {
+	merged := CloneBindings(b.bindings)
+	for input, action := range bindings {
+		if existing, exists := merged[input]; exists && existing != action {
+			return fmt.Errorf("input %s is already bound to a different action", input)
+		}
+		merged[input] = action
+	}
+	if err := expandModBindings(make(map[KeyInput]KeyAction, 2*len(merged)), merged); err != nil {
+		return err
+	}
+	b.bindings = merged
+	return nil
+}
+
+// Bindings returns a copy of the bindings built so far.
+func (b *BindingsBuilder) Bindings() map[KeyInput]KeyAction {
+	return CloneBindings(b.bindings)
+}
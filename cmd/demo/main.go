@@ -12,11 +12,20 @@ import (
 )
 
 var (
-	flagFont  = flag.String("font", "", "load font from file path (otherwise use built-in font)")
-	flagHiDPI = flag.Bool("hiDPI", false, "enable high-DPI display support")
-	flagVsync = flag.Bool("vsync", false, "enable sync on vertical blank (VSYNC)")
+	flagDPIAwareness = flag.String("dpiAwareness", "", "DPI awareness on Windows: unaware, system, permonitor, or permonitorv2 (otherwise use SDL's default)")
+	flagFont         = flag.String("font", "", "load font from file path (otherwise use built-in font)")
+	flagHiDPI        = flag.Bool("hiDPI", false, "enable high-DPI display support")
+	flagVsync        = flag.Bool("vsync", false, "enable sync on vertical blank (VSYNC)")
 )
 
+var dpiAwareness = map[string]nksdl.DPIAwareness{
+	"":             nksdl.DPIAwarenessDefault,
+	"unaware":      nksdl.DPIAwarenessUnaware,
+	"system":       nksdl.DPIAwarenessSystem,
+	"permonitor":   nksdl.DPIAwarenessPerMonitor,
+	"permonitorv2": nksdl.DPIAwarenessPerMonitorV2,
+}
+
 func init() {
 	runtime.LockOSThread()
 }
@@ -31,10 +40,9 @@ func main() {
 
 func run() (err error) {
 	sdl.LogSetAllPriority(sdl.LOG_PRIORITY_DEBUG)
-	windowFlags := uint32(0)
-	if *flagHiDPI {
-		sdl.SetHint(sdl.HINT_VIDEO_HIGHDPI_DISABLED, "0")
-		windowFlags |= sdl.WINDOW_ALLOW_HIGHDPI
+	awareness, ok := dpiAwareness[*flagDPIAwareness]
+	if !ok {
+		return fmt.Errorf("unknown DPI awareness %q", *flagDPIAwareness)
 	}
 	renderFlags := uint32(0)
 	if *flagVsync {
@@ -42,13 +50,17 @@ func run() (err error) {
 	}
 	sdlDriver := nksdl.DefaultSDLDriver{
 		InitFlags: sdl.INIT_EVERYTHING,
+		DPI: nksdl.DPIOpts{
+			HighDPI:   *flagHiDPI,
+			Awareness: awareness,
+			Scaling:   *flagHiDPI,
+		},
 		Window: nksdl.WindowOpts{
 			Title:  "go-nk-sdl demo",
 			PosX:   sdl.WINDOWPOS_CENTERED,
 			PosY:   sdl.WINDOWPOS_CENTERED,
 			Width:  800,
 			Height: 600,
-			Flags:  windowFlags,
 		},
 		Render: nksdl.RenderOpts{
 			Flags: renderFlags,
@@ -87,14 +99,7 @@ func run() (err error) {
 	editBuf := make([]byte, 256)
 	editLen := 0
 	for {
-		driver.SetBGColor(
-			sdl.Color{
-				R: uint8(color.R * 255),
-				B: uint8(color.B * 255),
-				G: uint8(color.G * 255),
-				A: uint8(color.A * 255),
-			},
-		)
+		driver.SetBGColor(nksdl.ColorFromNkf(color))
 		if err := driver.FrameStart(); err == nksdl.ErrQuit {
 			break
 		} else if err != nil {
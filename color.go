@@ -0,0 +1,91 @@
+package nksdl
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// ColorToNk converts c to nk.Color.
+func ColorToNk(c sdl.Color) nk.Color {
+	return nk.Color{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+// ColorFromNk converts c to sdl.Color.
+func ColorFromNk(c nk.Color) sdl.Color {
+	return sdl.Color{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+// ColorToNkf converts c to nk.Colorf, with components scaled from 0 to 1.
+func ColorToNkf(c sdl.Color) nk.Colorf {
+	return nk.Colorf{
+		R: float32(c.R) / 255,
+		G: float32(c.G) / 255,
+		B: float32(c.B) / 255,
+		A: float32(c.A) / 255,
+	}
+}
+
+// ColorFromNkf converts c to sdl.Color. Components of c outside of the range
+// from 0 to 1 are clamped to it.
+func ColorFromNkf(c nk.Colorf) sdl.Color {
+	return sdl.Color{
+		R: unitToUint8(c.R),
+		G: unitToUint8(c.G),
+		B: unitToUint8(c.B),
+		A: unitToUint8(c.A),
+	}
+}
+
+func unitToUint8(f float32) uint8 {
+	// written so that NaN maps to 0
+	if !(f > 0) {
+		return 0
+	} else if f >= 1 {
+		return 255
+	}
+	return uint8(f*255 + 0.5)
+}
+
+// ParseHexColor parses a color in CSS hexadecimal notation, i.e. a '#' followed
+// by 3 ("#rgb"), 4 ("#rgba"), 6 ("#rrggbb"), or 8 ("#rrggbbaa") hex digits. If
+// alpha is not given, the color is opaque.
+func ParseHexColor(s string) (sdl.Color, error) {
+	if len(s) == 0 || s[0] != '#' {
+		return sdl.Color{}, fmt.Errorf("hex color %q does not start with '#'", s)
+	}
+	digits := s[1:]
+	var width int
+	switch len(digits) {
+	case 3, 4:
+		width = 1
+	case 6, 8:
+		width = 2
+	default:
+		return sdl.Color{}, fmt.Errorf("hex color %q must have 3, 4, 6, or 8 digits", s)
+	}
+	components := [4]uint8{3: 255}
+	for i := 0; i < len(digits)/width; i++ {
+		value, err := strconv.ParseUint(digits[i*width:(i+1)*width], 16, 8)
+		if err != nil {
+			return sdl.Color{}, fmt.Errorf("hex color %q has invalid digits", s)
+		}
+		if width == 1 {
+			// expand e.g. "f" to "ff"
+			value *= 0x11
+		}
+		components[i] = uint8(value)
+	}
+	return sdl.Color{R: components[0], G: components[1], B: components[2], A: components[3]}, nil
+}
+
+// FormatHexColor formats c in CSS hexadecimal notation, as "#rrggbb" if c is
+// opaque or "#rrggbbaa" otherwise.
+func FormatHexColor(c sdl.Color) string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
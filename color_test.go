@@ -0,0 +1,90 @@
+package nksdl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestColorNkRoundTrip(t *testing.T) {
+	colors := []sdl.Color{
+		{},
+		{R: 255, G: 255, B: 255, A: 255},
+		{R: 1, G: 2, B: 3, A: 4},
+		{R: 128, G: 64, B: 32, A: 16},
+	}
+	for _, c := range colors {
+		if got := ColorFromNk(ColorToNk(c)); got != c {
+			t.Errorf("ColorFromNk(ColorToNk(%v)) = %v", c, got)
+		}
+		if got := ColorFromNkf(ColorToNkf(c)); got != c {
+			t.Errorf("ColorFromNkf(ColorToNkf(%v)) = %v", c, got)
+		}
+	}
+}
+
+func TestColorFromNkf(t *testing.T) {
+	nan := float32(math.NaN())
+	tests := []struct {
+		in   nk.Colorf
+		want sdl.Color
+	}{
+		{nk.Colorf{R: 0, G: 0.5, B: 1, A: 1}, sdl.Color{R: 0, G: 128, B: 255, A: 255}},
+		{nk.Colorf{R: -1, G: 2, B: nan, A: 0.2}, sdl.Color{R: 0, G: 255, B: 0, A: 51}},
+	}
+	for _, test := range tests {
+		if got := ColorFromNkf(test.in); got != test.want {
+			t.Errorf("ColorFromNkf(%v) = %v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  sdl.Color
+		valid bool
+	}{
+		{"#fff", sdl.Color{R: 255, G: 255, B: 255, A: 255}, true},
+		{"#1234", sdl.Color{R: 0x11, G: 0x22, B: 0x33, A: 0x44}, true},
+		{"#a0b1c2", sdl.Color{R: 0xa0, G: 0xb1, B: 0xc2, A: 255}, true},
+		{"#A0B1C2D3", sdl.Color{R: 0xa0, G: 0xb1, B: 0xc2, A: 0xd3}, true},
+		{"", sdl.Color{}, false},
+		{"fff", sdl.Color{}, false},
+		{"#", sdl.Color{}, false},
+		{"#ff", sdl.Color{}, false},
+		{"#fffff", sdl.Color{}, false},
+		{"#ggg", sdl.Color{}, false},
+		{"#+f0000", sdl.Color{}, false},
+	}
+	for _, test := range tests {
+		got, err := ParseHexColor(test.in)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("ParseHexColor(%q) returned %v, want valid=%t", test.in, err, test.valid)
+		} else if got != test.want {
+			t.Errorf("ParseHexColor(%q) = %v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestFormatHexColor(t *testing.T) {
+	tests := []struct {
+		in   sdl.Color
+		want string
+	}{
+		{sdl.Color{R: 0xa0, G: 0xb1, B: 0xc2, A: 255}, "#a0b1c2"},
+		{sdl.Color{R: 0xa0, G: 0xb1, B: 0xc2, A: 0}, "#a0b1c200"},
+		{sdl.Color{R: 1, G: 2, B: 3, A: 4}, "#01020304"},
+	}
+	for _, test := range tests {
+		got := FormatHexColor(test.in)
+		if got != test.want {
+			t.Errorf("FormatHexColor(%v) = %q, want %q", test.in, got, test.want)
+		}
+		if parsed, err := ParseHexColor(got); err != nil || parsed != test.in {
+			t.Errorf("ParseHexColor(%q) = %v, %v, want %v", got, parsed, err, test.in)
+		}
+	}
+}
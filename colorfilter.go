@@ -0,0 +1,181 @@
+package nksdl
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// ColorFilter identifies a color transformation applied to whole frames.
+type ColorFilter int32
+
+const (
+	// ColorFilterNone applies no transformation.
+	ColorFilterNone ColorFilter = iota
+	// ColorFilterProtanopia simulates protanopia (no red cones).
+	ColorFilterProtanopia
+	// ColorFilterDeuteranopia simulates deuteranopia (no green cones).
+	ColorFilterDeuteranopia
+	// ColorFilterTritanopia simulates tritanopia (no blue cones).
+	ColorFilterTritanopia
+	// ColorFilterGrayscale removes all color, which shows whether the GUI
+	// relies on color alone to convey information.
+	ColorFilterGrayscale
+	colorFilterCount
+)
+
+// colorFilterMatrices are the matrices, applied to RGB components, for each
+// ColorFilter. The simulations are common linear approximations, which are
+// adequate for checking that a GUI remains legible, but are not exact models
+// of color vision deficiency.
+var colorFilterMatrices = [colorFilterCount][3][3]float32{
+	ColorFilterProtanopia: {
+		{0.567, 0.433, 0},
+		{0.558, 0.442, 0},
+		{0, 0.242, 0.758},
+	},
+	ColorFilterDeuteranopia: {
+		{0.625, 0.375, 0},
+		{0.7, 0.3, 0},
+		{0, 0.3, 0.7},
+	},
+	ColorFilterTritanopia: {
+		{0.95, 0.05, 0},
+		{0, 0.433, 0.567},
+		{0, 0.475, 0.525},
+	},
+	ColorFilterGrayscale: {
+		{0.299, 0.587, 0.114},
+		{0.299, 0.587, 0.114},
+		{0.299, 0.587, 0.114},
+	},
+}
+
+// colorFilterState holds the resources of the color filter pass.
+type colorFilterState struct {
+	filter ColorFilter
+	tex    *sdl.Texture // streaming texture the filtered frame is uploaded to
+	texW   int32
+	texH   int32
+	pixels []byte // RGBA32 pixels of the frame
+}
+
+func (d *Driver) ColorFilter() ColorFilter {
+	return d.colorFilter.filter
+}
+
+// SetColorFilter sets a color transformation to apply to every frame, after
+// the GUI has been rendered and before the renderer is presented, e.g. to
+// check how the GUI looks to users with color vision deficiency. The filter
+// applies to the whole window, including the application's own rendering.
+//
+// Filtering is done on the CPU: every frame is read back from the renderer,
+// transformed, and uploaded to a texture which is then drawn over the frame.
+// Reading back stalls the GPU and costs several milliseconds per frame at
+// typical window sizes, so the filter is off by default, and is intended for
+// testing and assistance rather than for routine use.
+func (d *Driver) SetColorFilter(filter ColorFilter) error {
+	if filter < 0 || filter >= colorFilterCount {
+		return fmt.Errorf("color filter %d is invalid", filter)
+	}
+	d.colorFilter.filter = filter
+	if filter == ColorFilterNone {
+		return d.destroyColorFilter()
+	}
+	return nil
+}
+
+// applyColorFilter applies the color filter, if any, to the frame rendered so
+// far.
+func (d *Driver) applyColorFilter() (err error) {
+	if d.colorFilter.filter == ColorFilterNone {
+		return nil
+	}
+	// read and write the whole output, in pixels
+	oldScaleX, oldScaleY := d.renderer.GetScale()
+	oldViewport := d.renderer.GetViewport()
+	defer func() {
+		if err2 := d.renderer.SetScale(oldScaleX, oldScaleY); err2 != nil && err == nil {
+			err = fmt.Errorf("restoring renderer scale: %w", err2)
+		}
+		if err2 := d.renderer.SetViewport(&oldViewport); err2 != nil && err == nil {
+			err = fmt.Errorf("restoring renderer viewport: %w", err2)
+		}
+	}()
+	if err := d.renderer.SetScale(1, 1); err != nil {
+		return fmt.Errorf("setting renderer scale: %w", err)
+	}
+	if err := d.renderer.SetViewport(nil); err != nil {
+		return fmt.Errorf("setting renderer viewport: %w", err)
+	}
+	width, height, err := d.renderer.GetOutputSize()
+	if err != nil {
+		return fmt.Errorf("getting renderer output size: %w", err)
+	} else if width == 0 || height == 0 {
+		return nil
+	}
+	if err := d.ensureColorFilterTexture(width, height); err != nil {
+		return err
+	}
+	pixels := d.colorFilter.pixels
+	if err := d.renderer.ReadPixels(nil, uint32(sdl.PIXELFORMAT_RGBA32), unsafe.Pointer(&pixels[0]), int(4*width)); err != nil {
+		return fmt.Errorf("reading pixels: %w", err)
+	}
+	m := &colorFilterMatrices[d.colorFilter.filter]
+	for i := 0; i < len(pixels); i += 4 {
+		r, g, b := float32(pixels[i]), float32(pixels[i+1]), float32(pixels[i+2])
+		pixels[i] = clampUint8(m[0][0]*r + m[0][1]*g + m[0][2]*b)
+		pixels[i+1] = clampUint8(m[1][0]*r + m[1][1]*g + m[1][2]*b)
+		pixels[i+2] = clampUint8(m[2][0]*r + m[2][1]*g + m[2][2]*b)
+	}
+	if err := d.colorFilter.tex.Update(nil, pixels, int(4*width)); err != nil {
+		return fmt.Errorf("uploading filtered pixels: %w", err)
+	}
+	if err := d.renderer.Copy(d.colorFilter.tex, nil, nil); err != nil {
+		return fmt.Errorf("copying filtered pixels: %w", err)
+	}
+	return nil
+}
+
+// ensureColorFilterTexture creates the texture and pixel buffer of the color
+// filter, or recreates them if the output size has changed.
+func (d *Driver) ensureColorFilterTexture(width, height int32) error {
+	if d.colorFilter.tex != nil && d.colorFilter.texW == width && d.colorFilter.texH == height {
+		return nil
+	}
+	if err := d.destroyColorFilter(); err != nil {
+		return err
+	}
+	tex, err := d.renderer.CreateTexture(uint32(sdl.PIXELFORMAT_RGBA32), sdl.TEXTUREACCESS_STREAMING, width, height)
+	if err != nil {
+		return fmt.Errorf("creating color filter texture: %w", err)
+	}
+	if err := tex.SetBlendMode(sdl.BLENDMODE_NONE); err != nil {
+		tex.Destroy()
+		return fmt.Errorf("setting color filter texture blend mode: %w", err)
+	}
+	d.colorFilter.tex, d.colorFilter.texW, d.colorFilter.texH = tex, width, height
+	d.colorFilter.pixels = make([]byte, 4*int(width)*int(height))
+	return nil
+}
+
+// destroyColorFilter frees the resources of the color filter, if any.
+func (d *Driver) destroyColorFilter() error {
+	tex := d.colorFilter.tex
+	d.colorFilter.tex, d.colorFilter.texW, d.colorFilter.texH = nil, 0, 0
+	d.colorFilter.pixels = nil
+	if tex != nil {
+		return tex.Destroy()
+	}
+	return nil
+}
+
+func clampUint8(f float32) uint8 {
+	if f <= 0 {
+		return 0
+	} else if f >= 255 {
+		return 255
+	}
+	return uint8(f + 0.5)
+}
@@ -0,0 +1,149 @@
+package nksdl
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// CursorStyle identifies the shape of the mouse cursor. The styles mirror those
+// of Nuklear (enum nk_style_cursor).
+type CursorStyle int32
+
+const (
+	CursorArrow CursorStyle = iota
+	CursorText
+	CursorMove
+	CursorResizeVertical
+	CursorResizeHorizontal
+	CursorResizeTopLeftDownRight
+	CursorResizeTopRightDownLeft
+	cursorStyleCount
+)
+
+// systemCursors maps each CursorStyle to the closest SDL system cursor.
+var systemCursors = [cursorStyleCount]sdl.SystemCursor{
+	CursorArrow:                  sdl.SYSTEM_CURSOR_ARROW,
+	CursorText:                   sdl.SYSTEM_CURSOR_IBEAM,
+	CursorMove:                   sdl.SYSTEM_CURSOR_SIZEALL,
+	CursorResizeVertical:         sdl.SYSTEM_CURSOR_SIZENS,
+	CursorResizeHorizontal:       sdl.SYSTEM_CURSOR_SIZEWE,
+	CursorResizeTopLeftDownRight: sdl.SYSTEM_CURSOR_SIZENWSE,
+	CursorResizeTopRightDownLeft: sdl.SYSTEM_CURSOR_SIZENESW,
+}
+
+// CursorRegion locates the image of a single cursor within a cursor atlas.
+type CursorRegion struct {
+	// Rect is the bounds of the cursor image within the atlas.
+	Rect sdl.Rect
+	// HotX and HotY locate the hot spot of the cursor, relative to Rect.
+	HotX, HotY int32
+}
+
+// cursorState caches the cursors created by Driver.
+type cursorState struct {
+	style  CursorStyle
+	system [cursorStyleCount]*sdl.Cursor // created on first use
+	custom [cursorStyleCount]*sdl.Cursor // created by LoadCursorAtlas
+
+	software bool  // whether the cursor is drawn by the renderer
+	inside   bool  // whether the mouse is in the window
+	x, y     int32 // position of the mouse in the window
+
+	remote []RemoteCursor // cursors of other users, see SetRemoteCursors
+}
+
+func (d *Driver) CursorStyle() CursorStyle {
+	return d.cursors.style
+}
+
+// SetCursorStyle sets the shape of the mouse cursor. Cursors loaded by
+// LoadCursorAtlas take precedence over the system cursors.
+func (d *Driver) SetCursorStyle(style CursorStyle) error {
+	if style < 0 || style >= cursorStyleCount {
+		return fmt.Errorf("cursor style %d is invalid", style)
+	}
+	cursor := d.cursors.custom[style]
+	if cursor == nil {
+		cursor = d.cursors.system[style]
+	}
+	if cursor == nil {
+		cursor = sdl.CreateSystemCursor(systemCursors[style])
+		if cursor == nil {
+			return fmt.Errorf("creating system cursor for style %d: %w", style, lastSDLError())
+		}
+		d.cursors.system[style] = cursor
+	}
+	sdl.SetCursor(cursor)
+	d.cursors.style = style
+	return nil
+}
+
+// LoadCursorAtlas creates color cursors for the given styles from a single
+// atlas surface containing all of their images, as located by layout. The
+// cursors replace any previously loaded from an atlas, and are freed by
+// Destroy. The atlas surface is not retained and may be freed by the caller
+// after LoadCursorAtlas returns.
+func (d *Driver) LoadCursorAtlas(atlas *sdl.Surface, layout map[CursorStyle]CursorRegion) (err error) {
+	var cursors [cursorStyleCount]*sdl.Cursor
+	defer func() {
+		if err != nil {
+			freeCursors(cursors[:])
+		}
+	}()
+	blendMode, err := atlas.GetBlendMode()
+	if err != nil {
+		return fmt.Errorf("getting cursor atlas blend mode: %w", err)
+	}
+	if err = atlas.SetBlendMode(sdl.BLENDMODE_NONE); err != nil {
+		return fmt.Errorf("setting cursor atlas blend mode: %w", err)
+	}
+	defer atlas.SetBlendMode(blendMode)
+	bounds := sdl.Rect{W: atlas.W, H: atlas.H}
+	for style, region := range layout {
+		if style < 0 || style >= cursorStyleCount {
+			return fmt.Errorf("cursor style %d is invalid", style)
+		}
+		if cursors[style], err = cutCursor(atlas, bounds, region); err != nil {
+			return fmt.Errorf("creating cursor for style %d: %w", style, err)
+		}
+	}
+	freeCursors(d.cursors.custom[:])
+	d.cursors.custom = cursors
+	return d.SetCursorStyle(d.cursors.style)
+}
+
+// cutCursor creates a color cursor from region of atlas, which has the given
+// bounds.
+func cutCursor(atlas *sdl.Surface, bounds sdl.Rect, region CursorRegion) (*sdl.Cursor, error) {
+	rect := region.Rect
+	if rect.Empty() {
+		return nil, fmt.Errorf("region %v is empty", rect)
+	} else if clipped, _ := bounds.Intersect(&rect); clipped != rect {
+		return nil, fmt.Errorf("region %v exceeds atlas bounds %v", rect, bounds)
+	} else if region.HotX < 0 || region.HotX >= rect.W || region.HotY < 0 || region.HotY >= rect.H {
+		return nil, fmt.Errorf("hot spot (%d, %d) is outside of region %v", region.HotX, region.HotY, rect)
+	}
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, rect.W, rect.H, 32, sdl.PIXELFORMAT_ARGB8888)
+	if err != nil {
+		return nil, fmt.Errorf("creating cursor surface: %w", err)
+	}
+	defer surface.Free()
+	if err := atlas.Blit(&rect, surface, nil); err != nil {
+		return nil, fmt.Errorf("copying cursor image: %w", err)
+	}
+	cursor := sdl.CreateColorCursor(surface, region.HotX, region.HotY)
+	if cursor == nil {
+		return nil, lastSDLError()
+	}
+	return cursor, nil
+}
+
+func freeCursors(cursors []*sdl.Cursor) {
+	for i, cursor := range cursors {
+		if cursor != nil {
+			sdl.FreeCursor(cursor)
+			cursors[i] = nil
+		}
+	}
+}
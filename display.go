@@ -0,0 +1,241 @@
+package nksdl
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// defaultRefreshRate is the refresh rate assumed when the actual refresh rate
+// of the display is unknown.
+const defaultRefreshRate = 60
+
+// The kinds of SDL_DISPLAYEVENT. go-sdl2 does not define them, so they are
+// declared here with SDL's values.
+const (
+	displayEventOrientation  = 1 // SDL_DISPLAYEVENT_ORIENTATION
+	displayEventConnected    = 2 // SDL_DISPLAYEVENT_CONNECTED
+	displayEventDisconnected = 3 // SDL_DISPLAYEVENT_DISCONNECTED
+)
+
+// DisplayRefreshRate returns the refresh rate, in Hz, of the display that the
+// window is on. If the refresh rate is unknown, it is assumed to be 60 Hz.
+func (d *Driver) DisplayRefreshRate() int {
+	if d.refreshRate == 0 {
+		return defaultRefreshRate
+	}
+	return d.refreshRate
+}
+
+// updateDisplay refreshes cached information about the display that the
+// window is on.
+func (d *Driver) updateDisplay() error {
+	d.refreshRate = 0
+	displayIndex, err := d.window.GetDisplayIndex()
+	if err != nil {
+		return fmt.Errorf("getting window display index: %w", err)
+	}
+	mode, err := sdl.GetCurrentDisplayMode(displayIndex)
+	if err != nil {
+		return fmt.Errorf("getting display mode of display %d: %w", displayIndex, err)
+	}
+	d.refreshRate = int(mode.RefreshRate)
+	return nil
+}
+
+// PixelDensity returns the number of pixels of the renderer's output per unit
+// of window size, e.g. 2 for a high-DPI window on macOS, or 0 if it is unknown.
+// SDL 2 has no event for changes of pixel density, so the Driver checks it
+// whenever the window is resized or moved, and reports a change to the event
+// listener as EventTypeWindowDensityChanged. The automatic render scale (see
+// SetRenderScale) is recomputed when the density changes, but not on resizes
+// which leave it unchanged.
+func (d *Driver) PixelDensity() float32 {
+	return d.density
+}
+
+// updateDensity refreshes the pixel density of the window, returning true if
+// it changed.
+func (d *Driver) updateDensity() bool {
+	renderW, _, err := d.renderer.GetOutputSize()
+	if err != nil {
+		sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "getting renderer output size: %s", err.Error())
+		return false
+	}
+	windowW, _ := d.window.GetSize()
+	if windowW <= 0 {
+		return false
+	}
+	density := float32(renderW) / float32(windowW)
+	if density == d.density {
+		return false
+	}
+	d.density = density
+	return true
+}
+
+// handleWindowEvent updates the state of d in response to a window event,
+// returning true if the pixel density of the window changed.
+func (d *Driver) handleWindowEvent(event *sdl.WindowEvent) (densityChanged bool) {
+	switch event.Event {
+	case sdl.WINDOWEVENT_MOVED, sdl.WINDOWEVENT_DISPLAY_CHANGED:
+		if err := d.updateDisplay(); err != nil {
+			sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "updating display info: %s", err.Error())
+		}
+		densityChanged = d.updateDensity()
+		if d.autoScale && (d.dpiScale || densityChanged) {
+			// the new display may have a different DPI or density
+			if err := d.computeUIScale(); err != nil {
+				sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "computing UI scale: %s", err.Error())
+			}
+		}
+	case sdl.WINDOWEVENT_LEAVE:
+		d.cursors.inside = false
+	case sdl.WINDOWEVENT_FOCUS_GAINED:
+		d.idle.focused = true
+	case sdl.WINDOWEVENT_FOCUS_LOST:
+		d.idle.focused = false
+		d.eventHandler.releaseHeldKey()
+	case sdl.WINDOWEVENT_SIZE_CHANGED:
+		densityChanged = d.updateDensity()
+		if d.autoScale && densityChanged {
+			if err := d.computeUIScale(); err != nil {
+				sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "computing UI scale: %s", err.Error())
+			}
+		}
+	}
+	return densityChanged
+}
+
+// handleDisplayEvent updates the state of d in response to a display being
+// connected, disconnected, or reoriented.
+func (d *Driver) handleDisplayEvent(event *sdl.DisplayEvent) {
+	switch event.Event {
+	case displayEventConnected, displayEventDisconnected, displayEventOrientation:
+	default:
+		return
+	}
+	if err := d.rescueWindow(); err != nil {
+		sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "keeping window on a display: %s", err.Error())
+	}
+	if err := d.updateDisplay(); err != nil {
+		sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "updating display info: %s", err.Error())
+	}
+	if d.autoScale {
+		if err := d.computeUIScale(); err != nil {
+			sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "computing UI scale: %s", err.Error())
+		}
+	}
+}
+
+// rescueWindow moves the window to the center of the primary display if it is
+// not on any display, e.g. because its display was disconnected.
+func (d *Driver) rescueWindow() error {
+	numDisplays, err := sdl.GetNumVideoDisplays()
+	if err != nil {
+		return fmt.Errorf("getting number of displays: %w", err)
+	}
+	var windowRect sdl.Rect
+	windowRect.X, windowRect.Y = d.window.GetPosition()
+	windowRect.W, windowRect.H = d.window.GetSize()
+	for i := 0; i < numDisplays; i++ {
+		bounds, err := sdl.GetDisplayBounds(i)
+		if err != nil {
+			return fmt.Errorf("getting bounds of display %d: %w", i, err)
+		}
+		if bounds.HasIntersection(&windowRect) {
+			return nil
+		}
+	}
+	d.window.SetPosition(sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED)
+	return nil
+}
+
+// SetFullscreenOnDisplay makes the window fill the display with the given
+// index, in borderless "fullscreen desktop" mode, which keeps the display's
+// current mode. This lets the user choose which display the application fills
+// in a multi-display setup. If the render scale is automatic (see
+// SetRenderScale), it is recomputed, since the display may have a different
+// pixel density. To leave fullscreen, call Window().SetFullscreen(0).
+func (d *Driver) SetFullscreenOnDisplay(displayIndex int) error {
+	numDisplays, err := sdl.GetNumVideoDisplays()
+	if err != nil {
+		return fmt.Errorf("getting number of displays: %w", err)
+	} else if displayIndex < 0 || displayIndex >= numDisplays {
+		return fmt.Errorf("display index %d is out of bounds (%d displays)", displayIndex, numDisplays)
+	}
+	bounds, err := sdl.GetDisplayBounds(displayIndex)
+	if err != nil {
+		return fmt.Errorf("getting bounds of display %d: %w", displayIndex, err)
+	}
+	// the window must be windowed to move it to another display
+	if err := d.window.SetFullscreen(0); err != nil {
+		return fmt.Errorf("leaving fullscreen: %w", err)
+	}
+	d.window.SetPosition(bounds.X, bounds.Y)
+	d.window.SetSize(bounds.W, bounds.H)
+	if err := d.window.SetFullscreen(sdl.WINDOW_FULLSCREEN_DESKTOP); err != nil {
+		return fmt.Errorf("entering fullscreen on display %d: %w", displayIndex, err)
+	}
+	if err := d.updateDisplay(); err != nil {
+		return fmt.Errorf("updating display info: %w", err)
+	}
+	if d.autoScale {
+		if err := d.computeUIScale(); err != nil {
+			return fmt.Errorf("computing UI scale: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *Driver) RenderScaleFromDPI() bool {
+	return d.dpiScale
+}
+
+// SetRenderScaleFromDPI sets whether the automatic render scale (see
+// SetRenderScale) takes the DPI of the window's display into account. By
+// default, the automatic scale is the ratio of the renderer's output size to
+// the window size, which reflects the scaling done by platforms like macOS
+// with high-DPI windows, but is 1 where the desktop is scaled without the
+// window knowing, as with some X11 and Wayland setups. If fromDPI is true and
+// that ratio is 1, the scale is instead the display's DPI divided by the
+// platform's reference DPI (72 on macOS, 96 elsewhere), rounded to the
+// nearest quarter and clamped to between 1 and 5. The DPI is queried with
+// SDL_GetDisplayDPI, which every supported version of SDL has, but whether it
+// is known depends on the video driver; if it is not, the ratio is used as
+// before. The scale is recomputed when the window moves to another display.
+func (d *Driver) SetRenderScaleFromDPI(fromDPI bool) error {
+	d.dpiScale = fromDPI
+	if d.autoScale && d.renderer != nil {
+		if err := d.computeUIScale(); err != nil {
+			return fmt.Errorf("computing UI scale: %w", err)
+		}
+	}
+	return nil
+}
+
+// displayDPIScale returns the render scale derived from the DPI of the
+// window's display, and whether the DPI is known.
+func (d *Driver) displayDPIScale() (float32, bool) {
+	displayIndex, err := d.window.GetDisplayIndex()
+	if err != nil {
+		return 0, false
+	}
+	ddpi, _, _, err := sdl.GetDisplayDPI(displayIndex)
+	if err != nil || ddpi <= 0 {
+		return 0, false
+	}
+	referenceDPI := float32(96)
+	if runtime.GOOS == "darwin" {
+		referenceDPI = 72
+	}
+	scale := float32(math.Round(float64(4*ddpi/referenceDPI))) / 4
+	if scale < 1 {
+		scale = 1
+	} else if scale > 5 {
+		scale = 5
+	}
+	return scale, true
+}
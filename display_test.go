@@ -0,0 +1,78 @@
+package nksdl
+
+import (
+	"testing"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestHandleWindowEventDensity(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   uint8
+		density float32 // density of the window before the event
+		want    bool
+	}{
+		{"resized unchanged", sdl.WINDOWEVENT_SIZE_CHANGED, 1, false},
+		{"resized changed", sdl.WINDOWEVENT_SIZE_CHANGED, 2, true},
+		{"moved unchanged", sdl.WINDOWEVENT_MOVED, 1, false},
+		{"moved changed", sdl.WINDOWEVENT_MOVED, 0.5, true},
+		{"unrelated", sdl.WINDOWEVENT_LEAVE, 2, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			td := newTestDriver(t)
+			// the test window always has a density of 1, so pretend it had
+			// another one before the event
+			td.density = test.density
+			if got := td.handleWindowEvent(&sdl.WindowEvent{Event: test.event}); got != test.want {
+				t.Errorf("handleWindowEvent returned %t, want %t", got, test.want)
+			}
+			want := test.density
+			if test.want {
+				want = 1
+			}
+			if got := td.PixelDensity(); got != want {
+				t.Errorf("PixelDensity() = %g after the event, want %g", got, want)
+			}
+		})
+	}
+}
+
+func TestWindowDensityChangedEvent(t *testing.T) {
+	td := newTestDriver(t)
+	var changes int
+	td.eventListener = func(event sdl.Event, eventType EventType, usedByNuklear bool) error {
+		if eventType == EventTypeWindowDensityChanged {
+			changes++
+		}
+		return nil
+	}
+	windowID, err := td.window.GetID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resize := func() {
+		t.Helper()
+		if err := td.push(&sdl.WindowEvent{
+			Type:     sdl.WINDOWEVENT,
+			WindowID: windowID,
+			Event:    sdl.WINDOWEVENT_SIZE_CHANGED,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := td.Frame(func(nkc *nk.Context) {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	td.density = 2
+	resize()
+	if changes != 1 {
+		t.Errorf("got %d density changes after a resize changing it, want 1", changes)
+	}
+	resize()
+	if changes != 1 {
+		t.Errorf("got %d density changes after a resize keeping it, want 1", changes)
+	}
+}
@@ -1,8 +1,10 @@
 package nksdl
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/kbolino/go-nk"
 	"github.com/veandco/go-sdl2/sdl"
@@ -31,6 +33,38 @@ var DefaultBindings = map[KeyInput]KeyAction{
 	{Code: sdl.K_z, Mod: sdl.KMOD_CTRL | sdl.KMOD_SHIFT}: {Key1: nk.KeyTextRedo},
 }
 
+// NumpadBindings bind the navigation keys of the numeric keypad, which are in
+// effect while NumLock is off, to the same actions as their counterparts in
+// DefaultBindings, along with the keypad's Enter key. While NumLock is on, the
+// keypad digits are reported as NumLock-modified (see KeysymInput), so they do
+// not match these bindings and type digits instead.
+var NumpadBindings = map[KeyInput]KeyAction{
+	{Code: sdl.K_KP_ENTER}: {Key1: nk.KeyEnter},
+	{Code: sdl.K_KP_7}:     {Key1: nk.KeyTextStart, Key2: nk.KeyScrollStart},
+	{Code: sdl.K_KP_1}:     {Key1: nk.KeyTextEnd, Key2: nk.KeyScrollEnd},
+	{Code: sdl.K_KP_9}:     {Key1: nk.KeyScrollUp},
+	{Code: sdl.K_KP_3}:     {Key1: nk.KeyScrollDown},
+	{Code: sdl.K_KP_8}:     {Key1: nk.KeyUp},
+	{Code: sdl.K_KP_2}:     {Key1: nk.KeyDown},
+	{Code: sdl.K_KP_4}:     {Key1: nk.KeyLeft},
+	{Code: sdl.K_KP_6}:     {Key1: nk.KeyRight},
+}
+
+// ExtendedBindings are DefaultBindings together with NumpadBindings.
+var ExtendedBindings = mergeBindings(DefaultBindings, NumpadBindings)
+
+// mergeBindings returns a new map with the bindings of all of the given maps,
+// with later maps taking precedence.
+func mergeBindings(bindingMaps ...map[KeyInput]KeyAction) map[KeyInput]KeyAction {
+	merged := make(map[KeyInput]KeyAction)
+	for _, bindings := range bindingMaps {
+		for input, action := range bindings {
+			merged[input] = action
+		}
+	}
+	return merged
+}
+
 // EventType represents the type of a handled event. This is not an exhaustive
 // list of event types, and only represents those that are recognized by
 // EventHandler, with a placeholder (EventTypeUnhandled) for all other types.
@@ -44,8 +78,104 @@ const (
 	EventTypeInputScroll
 	EventTypeInputKey
 	EventTypeInputUnicode
+	EventTypeDisplay
+	EventTypeWindowShown
+	EventTypeWindowHidden
+	EventTypeWindowExposed
+	EventTypeWindowMoved
+	EventTypeWindowResized
+	EventTypeWindowMinimized
+	EventTypeWindowMaximized
+	EventTypeWindowRestored
+	EventTypeWindowFocusGained
+	EventTypeWindowFocusLost
+	EventTypeWindowEnter
+	EventTypeWindowLeave
+	EventTypeWindowClose
+	// EventTypeInputEditing is the type of IME composition events, which
+	// report text that is being composed but not yet committed. Committed text
+	// is reported as EventTypeInputUnicode.
+	EventTypeInputEditing
+	// EventTypeWake is the type of the events pushed by Driver.Wake, which
+	// only serve to wake the Driver.
+	EventTypeWake
+	// EventTypeWindowDensityChanged is reported, after the window event that
+	// caused it, when the pixel density of the window changes (see
+	// Driver.PixelDensity).
+	EventTypeWindowDensityChanged
 )
 
+// windowEventTypes maps the common kinds of sdl.WindowEvent to EventType.
+// EventTypeWindowResized is mapped from WINDOWEVENT_SIZE_CHANGED, which is sent
+// for every resize, rather than WINDOWEVENT_RESIZED, which is only sent for
+// resizes not made through the SDL API and is followed by the former anyway.
+var windowEventTypes = map[uint8]EventType{
+	sdl.WINDOWEVENT_SHOWN:        EventTypeWindowShown,
+	sdl.WINDOWEVENT_HIDDEN:       EventTypeWindowHidden,
+	sdl.WINDOWEVENT_EXPOSED:      EventTypeWindowExposed,
+	sdl.WINDOWEVENT_MOVED:        EventTypeWindowMoved,
+	sdl.WINDOWEVENT_SIZE_CHANGED: EventTypeWindowResized,
+	sdl.WINDOWEVENT_MINIMIZED:    EventTypeWindowMinimized,
+	sdl.WINDOWEVENT_MAXIMIZED:    EventTypeWindowMaximized,
+	sdl.WINDOWEVENT_RESTORED:     EventTypeWindowRestored,
+	sdl.WINDOWEVENT_FOCUS_GAINED: EventTypeWindowFocusGained,
+	sdl.WINDOWEVENT_FOCUS_LOST:   EventTypeWindowFocusLost,
+	sdl.WINDOWEVENT_ENTER:        EventTypeWindowEnter,
+	sdl.WINDOWEVENT_LEAVE:        EventTypeWindowLeave,
+	sdl.WINDOWEVENT_CLOSE:        EventTypeWindowClose,
+}
+
+// IsInput returns true if t is the type of a user input event.
+func (t EventType) IsInput() bool {
+	return t >= EventTypeInputMotion && t <= EventTypeInputUnicode || t == EventTypeInputEditing
+}
+
+// IsWindow returns true if t is the type of a window event.
+func (t EventType) IsWindow() bool {
+	return t >= EventTypeWindowShown && t <= EventTypeWindowClose || t == EventTypeWindowDensityChanged
+}
+
+// FrameInput summarizes the input events that Nuklear consumed in a frame,
+// i.e. those for which EventHandler.HandleEvent reported usedByNuklear.
+type FrameInput struct {
+	// MouseEvents counts mouse motion, button, and wheel events.
+	MouseEvents int
+	// KeyEvents counts key events which were bound to Nuklear actions.
+	KeyEvents int
+	// TextEvents counts text input events.
+	TextEvents int
+}
+
+// MouseConsumed returns true if Nuklear consumed any mouse events.
+func (fi FrameInput) MouseConsumed() bool {
+	return fi.MouseEvents != 0
+}
+
+// KeyboardConsumed returns true if Nuklear consumed any key events.
+func (fi FrameInput) KeyboardConsumed() bool {
+	return fi.KeyEvents != 0
+}
+
+// TextConsumed returns true if Nuklear consumed any text input events.
+func (fi FrameInput) TextConsumed() bool {
+	return fi.TextEvents != 0
+}
+
+// count counts an event of the given type, if it was used by Nuklear.
+func (fi *FrameInput) count(eventType EventType, usedByNuklear bool) {
+	if !usedByNuklear {
+		return
+	}
+	switch eventType {
+	case EventTypeInputMotion, EventTypeInputButton, EventTypeInputScroll:
+		fi.MouseEvents++
+	case EventTypeInputKey:
+		fi.KeyEvents++
+	case EventTypeInputUnicode:
+		fi.TextEvents++
+	}
+}
+
 // KeyInput is the reduced form of sdl.Keysym containing only the keycode and
 // modifiers, used to match input events.
 type KeyInput struct {
@@ -99,12 +229,29 @@ func appendMod(dst *strings.Builder, mod, mask sdl.Keymod, name string) bool {
 	return true
 }
 
-// KeysymInput converts sym to KeyInput.
+// numLockKeys are the keypad keys whose meaning depends on NumLock.
+var numLockKeys = map[sdl.Keycode]bool{
+	sdl.K_KP_0: true, sdl.K_KP_1: true, sdl.K_KP_2: true, sdl.K_KP_3: true,
+	sdl.K_KP_4: true, sdl.K_KP_5: true, sdl.K_KP_6: true, sdl.K_KP_7: true,
+	sdl.K_KP_8: true, sdl.K_KP_9: true, sdl.K_KP_PERIOD: true,
+}
+
+// lockMods are the modifiers reported for lock keys, which are on or off
+// rather than held, and so are ignored by key bindings and hotkeys.
+const lockMods = sdl.KMOD_NUM | sdl.KMOD_CAPS | sdl.KMOD_MODE
+
+// KeysymInput converts sym to KeyInput. The CapsLock, NumLock, and Mode
+// modifiers are dropped, so that bindings match regardless of whether they are
+// on, except for NumLock on the keypad digits and period, whose meaning it
+// changes.
 func KeysymInput(sym sdl.Keysym) KeyInput {
-	mod := sym.Mod
+	drop := sdl.Keymod(lockMods)
+	if numLockKeys[sym.Sym] {
+		drop &^= sdl.KMOD_NUM
+	}
 	return KeyInput{
 		Code: sym.Sym,
-		Mod:  sdl.Keymod(mod),
+		Mod:  sdl.Keymod(sym.Mod) &^ drop,
 	}
 }
 
@@ -114,12 +261,136 @@ func KeysymInput(sym sdl.Keysym) KeyInput {
 type KeyAction struct {
 	Key1 nk.Key
 	Key2 nk.Key
+	// Exact opts the binding out of the expansion of generic modifiers (see
+	// NewEventHandler), so that it matches its modifiers exactly. An exact
+	// binding takes precedence over bindings expanded from generic modifiers:
+	// e.g. an exact binding for RAlt+X overrides the RAlt+X binding expanded
+	// from a binding for Alt+X, while LAlt+X keeps the action of Alt+X. An
+	// exact binding with a generic modifier like sdl.KMOD_CTRL only matches
+	// when both the left and right variants are held.
+	Exact bool
+	// Repeat opts the binding into key repeat managed by the EventHandler (see
+	// EventHandler.SetKeyRepeatOpts), e.g. for navigation keys, so that holding
+	// the key repeats its actions at the same rate on every platform.
+	Repeat bool
+}
+
+// ClickOpts sets options for how EventHandler reports repeated mouse clicks.
+// SDL counts consecutive clicks made within the platform's double-click time
+// and distance, and the thresholds here are compared against that count. A
+// threshold of zero disables the corresponding behavior.
+type ClickOpts struct {
+	// DoubleClicks is the click count at which a left click is also reported
+	// to Nuklear as nk.ButtonDouble. Nuklear itself selects words with a right
+	// click in edit widgets, and offers no way to do so on double click.
+	DoubleClicks uint8
+	// TripleClicks is the click count at which a left click also selects all
+	// of the text in the active edit widget (via nk.KeyTextSelectAll), which
+	// for a single-line edit is the whole line. Clicks at or beyond this count
+	// are no longer reported as double clicks.
+	TripleClicks uint8
+	// DoubleClickButtons is the set of buttons whose repeated clicks are
+	// reported as nk.ButtonDouble, as well as the button itself. Nuklear has
+	// only one double-click button and cannot tell which button was double
+	// clicked. Zero means ClickLeft.
+	DoubleClickButtons ClickButtons
+}
+
+// ClickButtons is a set of mouse buttons.
+type ClickButtons uint8
+
+const (
+	ClickLeft ClickButtons = 1 << iota
+	ClickRight
+	ClickMiddle
+)
+
+// doubleClickButtons returns the buttons whose double clicks are reported.
+func (opts ClickOpts) doubleClickButtons() ClickButtons {
+	if opts.DoubleClickButtons == 0 {
+		return ClickLeft
+	}
+	return opts.DoubleClickButtons
 }
 
+// DefaultClickOpts are the ClickOpts used by NewEventHandler.
+var DefaultClickOpts = ClickOpts{DoubleClicks: 2, TripleClicks: 3}
+
+// countClicks returns whether a click that SDL counted as the given number of
+// consecutive clicks reaches the double and triple click thresholds.
+func (opts ClickOpts) countClicks(clicks uint8) (double, triple bool) {
+	double = opts.DoubleClicks != 0 && clicks >= opts.DoubleClicks
+	triple = opts.TripleClicks != 0 && clicks >= opts.TripleClicks
+	return double, triple
+}
+
+// ScrollOpts sets options for how EventHandler reports scrolling.
+type ScrollOpts struct {
+	// Threshold is the magnitude, in wheel notches, that scroll deltas must
+	// accumulate to before they are reported to Nuklear. Trackpads report
+	// many tiny fractional deltas, and Nuklear rounds scroll offsets down to
+	// whole pixels, so each of them alone may scroll nothing at all. Deltas
+	// are accumulated across events and frames, and reported together once
+	// either axis reaches the threshold. A Threshold of zero reports every
+	// delta as it arrives.
+	Threshold float32
+	// Stepped, if true, reports scrolling in discrete steps instead, e.g. for
+	// a value spinner that should step once per notch even with a trackpad or
+	// other precise device: every Threshold of accumulated delta on an axis is
+	// reported as a delta of exactly 1 on that axis, and the remainder is kept
+	// for later. Threshold must be positive if Stepped is true; a Threshold of
+	// 1 makes one step per wheel notch.
+	Stepped bool
+}
+
+// DefaultScrollOpts are the ScrollOpts used by NewEventHandler.
+var DefaultScrollOpts = ScrollOpts{Threshold: 0.1}
+
 // EventHandler is used to handle input events from SDL and report them to an
 // nk.Context.
 type EventHandler struct {
 	bindings map[KeyInput]KeyAction
+	clicks   ClickOpts
+	scroll   ScrollOpts
+	debug    bool // whether to log input reported to Nuklear
+
+	scrollX, scrollY float32 // scroll deltas accumulated but not yet reported
+
+	keyRepeat KeyRepeatOpts
+	held      heldKey // key whose actions are repeated
+
+	sparseMotion bool      // whether motion without buttons is deferred
+	motion       sdl.Point // last deferred motion
+	motionSet    bool      // whether motion holds a deferred motion
+
+	originX, originY int32       // origin of Nuklear coordinates in the window
+	orient           orientation // rotation and flip of the GUI
+
+	composition composition // IME composition in progress
+
+	mirrors []*nk.Context // contexts which receive the same input as nkc
+
+	wakeType    uint32 // SDL event type of wake events, 0 if not registered
+	wakePending int32  // 1 if a wake event is in the queue, accessed atomically
+
+	trace func(input string) // receives the input logged in debug mode, for tests
+}
+
+// composition is the state of an IME composition.
+type composition struct {
+	text          string
+	start, length int32
+}
+
+// Composition returns the text being composed with an input method (IME), if
+// any, along with the position and length, in runes, of the part of it being
+// edited. Nuklear knows nothing of compositions and only receives text once
+// it is committed, so an application that supports IMEs can show the
+// composition itself, e.g. in a tooltip next to the active edit widget, to
+// keep the user from typing blind. Text is empty when no composition is in
+// progress.
+func (h *EventHandler) Composition() (text string, start, length int32) {
+	return h.composition.text, h.composition.start, h.composition.length
 }
 
 // NewEventHandler creates a new EventHandler from the given bindings. The map
@@ -127,44 +398,171 @@ type EventHandler struct {
 // left and right key bindings. For example, if there is a binding with Ctrl
 // modifier set it will be expanded into separate bindings to the same action
 // for both LCtrl and RCtrl instead. NewEventHandler panics if the resulting
-// bindings conflict.
+// bindings conflict, except that bindings marked as KeyAction.Exact are not
+// expanded and take precedence over expanded bindings.
 func NewEventHandler(bindings map[KeyInput]KeyAction) EventHandler {
 	bindingsCopy := make(map[KeyInput]KeyAction, 2*len(bindings))
-	expandModBindings(bindingsCopy, bindings)
-	return EventHandler{bindingsCopy}
+	if err := expandModBindings(bindingsCopy, bindings); err != nil {
+		panic(err)
+	}
+	return EventHandler{
+		bindings: bindingsCopy,
+		clicks:   DefaultClickOpts,
+		scroll:   DefaultScrollOpts,
+	}
+}
+
+func (h *EventHandler) ClickOpts() ClickOpts {
+	return h.clicks
+}
+
+// SetClickOpts sets the thresholds for reporting repeated clicks. If both
+// thresholds are non-zero, TripleClicks must be greater than DoubleClicks.
+func (h *EventHandler) SetClickOpts(opts ClickOpts) error {
+	if opts.DoubleClicks != 0 && opts.TripleClicks != 0 && opts.TripleClicks <= opts.DoubleClicks {
+		return fmt.Errorf("TripleClicks(%d) must be greater than DoubleClicks(%d)",
+			opts.TripleClicks, opts.DoubleClicks)
+	}
+	h.clicks = opts
+	return nil
+}
+
+func (h *EventHandler) ScrollOpts() ScrollOpts {
+	return h.scroll
+}
+
+// SetScrollOpts sets the options for reporting scrolling. Any scroll deltas
+// accumulated so far are discarded.
+func (h *EventHandler) SetScrollOpts(opts ScrollOpts) error {
+	if opts.Threshold < 0 {
+		return fmt.Errorf("scroll Threshold(%g) is negative", opts.Threshold)
+	} else if opts.Stepped && opts.Threshold == 0 {
+		return errors.New("scroll Threshold must be positive if Stepped is true")
+	}
+	h.scroll = opts
+	h.scrollX, h.scrollY = 0, 0
+	return nil
+}
+
+func (h *EventHandler) SparseMotion() bool {
+	return h.sparseMotion
+}
+
+// SetSparseMotion sets whether mouse motion is reported to Nuklear sparsely,
+// which saves work in GUIs that mostly respond to clicks, like a simple
+// control panel. Motion while a button is held is still reported as it
+// happens, so that dragging works, but other motion is deferred: only the last
+// deferred position is reported, right before the next button event or at the
+// end of the frame (see FlushMotion). Hover effects therefore still follow the
+// cursor, but only by its position at the end of each frame, so a widget that
+// the cursor crosses within a single frame is never hovered. Sparse motion is
+// off by default.
+func (h *EventHandler) SetSparseMotion(sparse bool) {
+	h.sparseMotion = sparse
+	if !sparse {
+		h.motionSet = false
+	}
+}
+
+// FlushMotion reports the deferred mouse motion, if any, to nkc (see
+// SetSparseMotion). It must be called once per frame between nkc.InputBegin
+// and nkc.InputEnd, after the events of the frame have been handled, which
+// Driver.FrameStart does.
+func (h *EventHandler) FlushMotion(nkc *nk.Context) {
+	if h.motionSet {
+		h.motionSet = false
+		h.inputMotion(nkc, h.motion.X, h.motion.Y)
+	}
+}
+
+// EventPosition returns the position of a mouse motion or button event in the
+// coordinates used by Nuklear, i.e. translated the same way as when the event
+// is reported to Nuklear, so that an EventListener can hit-test the event
+// against its own layout consistently with the GUI. The translation accounts
+// for the GUI viewport (see Driver.SetGUIViewport) and the orientation (see
+// Driver.SetOrientation); SDL itself maps the event to the logical size, if
+// one is set. The event itself is left as it is. The return value ok is false
+// for other types of events.
+func (h *EventHandler) EventPosition(event sdl.Event) (x, y int32, ok bool) {
+	switch e := event.(type) {
+	case *sdl.MouseMotionEvent:
+		x, y = h.toNuklear(e.X, e.Y)
+	case *sdl.MouseButtonEvent:
+		x, y = h.toNuklear(e.X, e.Y)
+	default:
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+// toNuklear translates (x, y) from window coordinates to the coordinates used
+// by Nuklear.
+func (h *EventHandler) toNuklear(x, y int32) (int32, int32) {
+	return h.orient.toGUI(x-h.originX, y-h.originY)
 }
 
 // HandleEvent handles the given event, reporting its actions to nkc, using
 // the defined bindings. The return value indicates the type of the event and
 // whether the event was used at all.
-func (h EventHandler) HandleEvent(nkc *nk.Context, event sdl.Event) (EventType, bool) {
+func (h *EventHandler) HandleEvent(nkc *nk.Context, event sdl.Event) (EventType, bool) {
 	switch e := event.(type) {
 	case *sdl.QuitEvent:
 		return EventTypeQuit, false
 	case *sdl.MouseMotionEvent:
-		x, y := e.X, e.Y
-		nkc.InputMotion(x, y)
+		x, y := h.toNuklear(e.X, e.Y)
+		if h.sparseMotion && e.State == 0 {
+			h.motion, h.motionSet = sdl.Point{X: x, Y: y}, true
+			return EventTypeInputMotion, true
+		}
+		h.motionSet = false
+		h.inputMotion(nkc, x, y)
 		return EventTypeInputMotion, true
 	case *sdl.MouseButtonEvent:
-		x, y := e.X, e.Y
+		x, y := h.toNuklear(e.X, e.Y)
+		h.FlushMotion(nkc)
 		down := false
 		if e.State == sdl.PRESSED {
 			down = true
 		}
+		var button nk.Button
+		var clickButton ClickButtons
 		switch e.Button {
 		case sdl.BUTTON_LEFT:
-			if e.Clicks == 2 {
-				nkc.InputButton(nk.ButtonDouble, x, y, down)
-			}
-			nkc.InputButton(nk.ButtonLeft, x, y, down)
+			button, clickButton = nk.ButtonLeft, ClickLeft
 		case sdl.BUTTON_RIGHT:
-			nkc.InputButton(nk.ButtonRight, x, y, down)
+			button, clickButton = nk.ButtonRight, ClickRight
 		case sdl.BUTTON_MIDDLE:
-			nkc.InputButton(nk.ButtonMiddle, x, y, down)
+			button, clickButton = nk.ButtonMiddle, ClickMiddle
+		default:
+			return EventTypeInputButton, true
+		}
+		double, triple := h.clicks.countClicks(e.Clicks)
+		triple = triple && button == nk.ButtonLeft
+		if double && !triple && h.clicks.doubleClickButtons()&clickButton != 0 {
+			h.inputButton(nkc, nk.ButtonDouble, x, y, down)
+		}
+		h.inputButton(nkc, button, x, y, down)
+		if triple {
+			h.inputKey(nkc, nk.KeyTextSelectAll, down)
 		}
 		return EventTypeInputButton, true
 	case *sdl.MouseWheelEvent:
-		nkc.InputScroll(e.PreciseX, e.PreciseY)
+		x, y := wheelDelta(e)
+		h.scrollX += x
+		h.scrollY += y
+		if h.scroll.Stepped {
+			// conversion to an integer truncates toward zero
+			stepsX := float32(int32(h.scrollX / h.scroll.Threshold))
+			stepsY := float32(int32(h.scrollY / h.scroll.Threshold))
+			if stepsX != 0 || stepsY != 0 {
+				h.inputScroll(nkc, stepsX, stepsY)
+				h.scrollX -= stepsX * h.scroll.Threshold
+				h.scrollY -= stepsY * h.scroll.Threshold
+			}
+		} else if abs32(h.scrollX) >= h.scroll.Threshold || abs32(h.scrollY) >= h.scroll.Threshold {
+			h.inputScroll(nkc, h.scrollX, h.scrollY)
+			h.scrollX, h.scrollY = 0, 0
+		}
 		return EventTypeInputScroll, true
 	case *sdl.KeyboardEvent:
 		var down bool
@@ -173,24 +571,61 @@ func (h EventHandler) HandleEvent(nkc *nk.Context, event sdl.Event) (EventType,
 		}
 		input := KeysymInput(e.Keysym)
 		action := h.bindings[input]
+		if h.debug {
+			h.logInput("key %s down=%t -> %s, %s", input, down, keyName(action.Key1), keyName(action.Key2))
+		}
+		if h.trackHeldKey(e, action) {
+			return EventTypeInputKey, true
+		}
 		if action.Key1 != nk.KeyNone {
-			nkc.InputKey(action.Key1, down)
+			h.inputKey(nkc, action.Key1, down)
 			if action.Key2 != nk.KeyNone {
-				nkc.InputKey(action.Key2, down)
+				h.inputKey(nkc, action.Key2, down)
 			}
 			return EventTypeInputKey, true
 		}
 		return EventTypeInputKey, false
+	case *sdl.TextEditingEvent:
+		h.composition = composition{text: e.GetText(), start: e.Start, length: e.Length}
+		if h.debug {
+			h.logInput("editing %q start=%d length=%d", h.composition.text, e.Start, e.Length)
+		}
+		return EventTypeInputEditing, false
 	case *sdl.TextInputEvent:
+		// committed text ends the composition
+		h.composition = composition{}
 		for _, r := range e.GetText() {
-			nkc.InputUnicode(r)
+			h.inputUnicode(nkc, r)
 		}
 		return EventTypeInputUnicode, true
+	case *sdl.DisplayEvent:
+		return EventTypeDisplay, false
+	case *sdl.UserEvent:
+		if h.wakeType != 0 && e.Type == h.wakeType {
+			atomic.StoreInt32(&h.wakePending, 0)
+			return EventTypeWake, false
+		}
+		return EventTypeUnhandled, false
+	case *sdl.WindowEvent:
+		if eventType, ok := windowEventTypes[e.Event]; ok {
+			return eventType, false
+		}
+		return EventTypeUnhandled, false
 	default:
 		return EventTypeUnhandled, false
 	}
 }
 
+// wheelDelta returns the precise scroll amounts of a mouse wheel event, negated
+// if the platform reports them flipped.
+func wheelDelta(e *sdl.MouseWheelEvent) (x, y float32) {
+	x, y = e.PreciseX, e.PreciseY
+	if e.Direction == sdl.MOUSEWHEEL_FLIPPED {
+		x, y = -x, -y
+	}
+	return x, y
+}
+
 type keyBinding struct {
 	input  KeyInput
 	action KeyAction
@@ -200,10 +635,16 @@ type keyBinding struct {
 // KMOD_CTRL into bindings for specific modifier variants like KMOD_LCTRL and
 // KMOD_RCTRL. The new bindings will be read from src and written to dst, which
 // may be different maps or the same map. Bindings that don't need to be
-// expanded will be written directly to dst.
-func expandModBindings(dst, src map[KeyInput]KeyAction) {
+// expanded will be written directly to dst. An error is returned if the
+// expanded bindings conflict.
+func expandModBindings(dst, src map[KeyInput]KeyAction) error {
 	var bindings []keyBinding
+	var exact []keyBinding
 	for input, action := range src {
+		if action.Exact {
+			exact = append(exact, keyBinding{input, action})
+			continue
+		}
 		bindings = bindings[:0]
 		bindings = append(bindings, keyBinding{input, action})
 		bindings = expandModBinding(bindings, sdl.KMOD_CTRL, sdl.KMOD_LCTRL, sdl.KMOD_RCTRL)
@@ -211,12 +652,20 @@ func expandModBindings(dst, src map[KeyInput]KeyAction) {
 		bindings = expandModBinding(bindings, sdl.KMOD_ALT, sdl.KMOD_LALT, sdl.KMOD_RALT)
 		bindings = expandModBinding(bindings, sdl.KMOD_GUI, sdl.KMOD_LGUI, sdl.KMOD_RGUI)
 		for _, binding := range bindings {
+			if srcAction, exists := src[binding.input]; exists && srcAction.Exact {
+				// exact bindings take precedence
+				continue
+			}
 			if dstBinding, exists := dst[binding.input]; exists && dstBinding != src[binding.input] {
-				panic(fmt.Errorf("conflicting binding: input %s is bound to two different actions", input))
+				return fmt.Errorf("conflicting binding: input %s is bound to two different actions", input)
 			}
 			dst[binding.input] = binding.action
 		}
 	}
+	for _, binding := range exact {
+		dst[binding.input] = binding.action
+	}
+	return nil
 }
 
 // expandModBinding implements expandModBindings for a single modifier key,
@@ -242,3 +691,10 @@ func expandModBinding(bindings []keyBinding, both, left, right sdl.Keymod) []key
 	}
 	return bindings
 }
+
+func abs32(x float32) float32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,369 @@
+package nksdl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// handleEvents passes events to h within a single round of input, and returns
+// the input that h reported to Nuklear, in the format of its debug log.
+func handleEvents(t *testing.T, h *EventHandler, events ...sdl.Event) []string {
+	t.Helper()
+	return traceInput(t, h, func(nkc *nk.Context) {
+		for _, event := range events {
+			h.HandleEvent(nkc, event)
+		}
+	})
+}
+
+// traceInput calls fn within a single round of input, and returns the input
+// that h reported to Nuklear during it, in the format of its debug log.
+func traceInput(t *testing.T, h *EventHandler, fn func(nkc *nk.Context)) []string {
+	t.Helper()
+	nkc, err := nk.NewContext()
+	if err != nil {
+		t.Fatalf("creating Nuklear context: %v", err)
+	}
+	defer nkc.Free()
+	var reported []string
+	h.debug = true
+	h.trace = func(input string) {
+		reported = append(reported, input)
+	}
+	defer func() {
+		h.debug = false
+		h.trace = nil
+	}()
+	nkc.InputBegin()
+	fn(nkc)
+	nkc.InputEnd()
+	return reported
+}
+
+// buttonDown returns an event for a press of button at (10, 20), which SDL
+// counts as the given number of consecutive clicks.
+func buttonDown(button uint8, clicks uint8) *sdl.MouseButtonEvent {
+	return &sdl.MouseButtonEvent{
+		Type:   sdl.MOUSEBUTTONDOWN,
+		Button: button,
+		State:  sdl.PRESSED,
+		Clicks: clicks,
+		X:      10,
+		Y:      20,
+	}
+}
+
+func TestClickOptsCountClicks(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   ClickOpts
+		clicks uint8
+		double bool
+		triple bool
+	}{
+		{"single", DefaultClickOpts, 1, false, false},
+		{"double", DefaultClickOpts, 2, true, false},
+		{"triple", DefaultClickOpts, 3, true, true},
+		{"quadruple", DefaultClickOpts, 4, true, true},
+		{"double disabled", ClickOpts{TripleClicks: 3}, 2, false, false},
+		{"double disabled triple", ClickOpts{TripleClicks: 3}, 3, false, true},
+		{"triple disabled", ClickOpts{DoubleClicks: 2}, 3, true, false},
+		{"both disabled", ClickOpts{}, 3, false, false},
+		{"double raised", ClickOpts{DoubleClicks: 3}, 2, false, false},
+		{"double at raised", ClickOpts{DoubleClicks: 3}, 3, true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			double, triple := test.opts.countClicks(test.clicks)
+			if double != test.double || triple != test.triple {
+				t.Errorf("countClicks(%d) = %t, %t, want %t, %t", test.clicks, double, triple, test.double, test.triple)
+			}
+		})
+	}
+}
+
+func TestHandleEventClicks(t *testing.T) {
+	const (
+		left   = "button Left down=true at (10, 20)"
+		right  = "button Right down=true at (10, 20)"
+		double = "button Double down=true at (10, 20)"
+		all    = "key TextSelectAll down=true"
+	)
+	tests := []struct {
+		name   string
+		opts   ClickOpts
+		button uint8
+		clicks uint8
+		want   []string
+	}{
+		{"single", DefaultClickOpts, sdl.BUTTON_LEFT, 1, []string{left}},
+		{"double", DefaultClickOpts, sdl.BUTTON_LEFT, 2, []string{double, left}},
+		{"triple", DefaultClickOpts, sdl.BUTTON_LEFT, 3, []string{left, all}},
+		{"quadruple", DefaultClickOpts, sdl.BUTTON_LEFT, 4, []string{left, all}},
+		{"right double", DefaultClickOpts, sdl.BUTTON_RIGHT, 2, []string{right}},
+		{"right triple", DefaultClickOpts, sdl.BUTTON_RIGHT, 3, []string{right}},
+		{"double disabled", ClickOpts{TripleClicks: 3}, sdl.BUTTON_LEFT, 2, []string{left}},
+		{"triple disabled", ClickOpts{DoubleClicks: 2}, sdl.BUTTON_LEFT, 3, []string{double, left}},
+		{"both disabled", ClickOpts{}, sdl.BUTTON_LEFT, 3, []string{left}},
+		{"double raised", ClickOpts{DoubleClicks: 3}, sdl.BUTTON_LEFT, 2, []string{left}},
+		{"double at raised", ClickOpts{DoubleClicks: 3}, sdl.BUTTON_LEFT, 3, []string{double, left}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := NewEventHandler(DefaultBindings)
+			if err := h.SetClickOpts(test.opts); err != nil {
+				t.Fatalf("SetClickOpts(%+v): %v", test.opts, err)
+			}
+			got := handleEvents(t, &h, buttonDown(test.button, test.clicks))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHandleEventDoubleClickButtons(t *testing.T) {
+	const (
+		left   = "button Left down=true at (10, 20)"
+		right  = "button Right down=true at (10, 20)"
+		middle = "button Middle down=true at (10, 20)"
+		double = "button Double down=true at (10, 20)"
+	)
+	tests := []struct {
+		name    string
+		buttons ClickButtons
+		button  uint8
+		want    []string
+	}{
+		{"default left", 0, sdl.BUTTON_LEFT, []string{double, left}},
+		{"default right", 0, sdl.BUTTON_RIGHT, []string{right}},
+		{"default middle", 0, sdl.BUTTON_MIDDLE, []string{middle}},
+		{"right only left", ClickRight, sdl.BUTTON_LEFT, []string{left}},
+		{"right only right", ClickRight, sdl.BUTTON_RIGHT, []string{double, right}},
+		{"all middle", ClickLeft | ClickRight | ClickMiddle, sdl.BUTTON_MIDDLE, []string{double, middle}},
+		{"unknown button", ClickLeft | ClickRight | ClickMiddle, sdl.BUTTON_X1, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := NewEventHandler(DefaultBindings)
+			opts := DefaultClickOpts
+			opts.DoubleClickButtons = test.buttons
+			if err := h.SetClickOpts(opts); err != nil {
+				t.Fatalf("SetClickOpts(%+v): %v", opts, err)
+			}
+			got := handleEvents(t, &h, buttonDown(test.button, 2))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetClickOpts(t *testing.T) {
+	tests := []struct {
+		opts  ClickOpts
+		valid bool
+	}{
+		{DefaultClickOpts, true},
+		{ClickOpts{}, true},
+		{ClickOpts{DoubleClicks: 2}, true},
+		{ClickOpts{TripleClicks: 2}, true},
+		{ClickOpts{DoubleClicks: 2, TripleClicks: 5}, true},
+		{ClickOpts{DoubleClicks: 3, TripleClicks: 3}, false},
+		{ClickOpts{DoubleClicks: 3, TripleClicks: 2}, false},
+	}
+	for _, test := range tests {
+		h := NewEventHandler(DefaultBindings)
+		err := h.SetClickOpts(test.opts)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("SetClickOpts(%+v) returned %v, want valid=%t", test.opts, err, test.valid)
+		} else if !valid && h.ClickOpts() != DefaultClickOpts {
+			t.Errorf("SetClickOpts(%+v) changed the options to %+v despite failing", test.opts, h.ClickOpts())
+		}
+	}
+}
+
+// wheel returns a mouse wheel event with the given precise deltas and
+// direction.
+func wheel(x, y float32, direction uint32) *sdl.MouseWheelEvent {
+	return &sdl.MouseWheelEvent{
+		Type:      sdl.MOUSEWHEEL,
+		X:         int32(x),
+		Y:         int32(y),
+		Direction: direction,
+		PreciseX:  x,
+		PreciseY:  y,
+	}
+}
+
+func TestWheelDelta(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *sdl.MouseWheelEvent
+		wantX float32
+		wantY float32
+	}{
+		{"normal", wheel(1, -2, sdl.MOUSEWHEEL_NORMAL), 1, -2},
+		{"normal precise", wheel(0.25, 0.5, sdl.MOUSEWHEEL_NORMAL), 0.25, 0.5},
+		{"flipped", wheel(1, -2, sdl.MOUSEWHEEL_FLIPPED), -1, 2},
+		{"flipped vertical", wheel(0, 3, sdl.MOUSEWHEEL_FLIPPED), 0, -3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			x, y := wheelDelta(test.event)
+			if x != test.wantX || y != test.wantY {
+				t.Errorf("wheelDelta = (%g, %g), want (%g, %g)", x, y, test.wantX, test.wantY)
+			}
+		})
+	}
+}
+
+func TestKeysymInputLockModifiers(t *testing.T) {
+	tests := []struct {
+		name   string
+		sym    sdl.Keysym
+		want   KeyInput
+		action KeyAction // expected binding in ExtendedBindings
+	}{
+		{
+			"caps lock",
+			sdl.Keysym{Sym: sdl.K_a, Mod: sdl.KMOD_LCTRL | sdl.KMOD_CAPS},
+			KeyInput{Code: sdl.K_a, Mod: sdl.KMOD_LCTRL},
+			KeyAction{},
+		},
+		{
+			"num lock",
+			sdl.Keysym{Sym: sdl.K_LEFT, Mod: sdl.KMOD_NUM},
+			KeyInput{Code: sdl.K_LEFT},
+			KeyAction{Key1: nk.KeyLeft},
+		},
+		{
+			"num lock with ctrl",
+			sdl.Keysym{Sym: sdl.K_z, Mod: sdl.KMOD_CTRL | sdl.KMOD_NUM | sdl.KMOD_CAPS},
+			KeyInput{Code: sdl.K_z, Mod: sdl.KMOD_CTRL},
+			KeyAction{Key1: nk.KeyTextUndo},
+		},
+		{
+			"mode",
+			sdl.Keysym{Sym: sdl.K_z, Mod: sdl.KMOD_CTRL | sdl.KMOD_MODE},
+			KeyInput{Code: sdl.K_z, Mod: sdl.KMOD_CTRL},
+			KeyAction{Key1: nk.KeyTextUndo},
+		},
+		{
+			"keypad up",
+			sdl.Keysym{Sym: sdl.K_KP_8},
+			KeyInput{Code: sdl.K_KP_8},
+			KeyAction{Key1: nk.KeyUp},
+		},
+		{
+			"keypad digit",
+			sdl.Keysym{Sym: sdl.K_KP_8, Mod: sdl.KMOD_NUM},
+			KeyInput{Code: sdl.K_KP_8, Mod: sdl.KMOD_NUM},
+			KeyAction{},
+		},
+		{
+			"keypad enter",
+			sdl.Keysym{Sym: sdl.K_KP_ENTER, Mod: sdl.KMOD_NUM},
+			KeyInput{Code: sdl.K_KP_ENTER},
+			KeyAction{Key1: nk.KeyEnter},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := KeysymInput(test.sym)
+			if got != test.want {
+				t.Errorf("KeysymInput(%+v) = %+v, want %+v", test.sym, got, test.want)
+			}
+			if action := ExtendedBindings[got]; action != test.action {
+				t.Errorf("ExtendedBindings[%+v] = %+v, want %+v", got, action, test.action)
+			}
+		})
+	}
+}
+
+func TestExtendedBindings(t *testing.T) {
+	for input, action := range DefaultBindings {
+		if got := ExtendedBindings[input]; got != action {
+			t.Errorf("ExtendedBindings[%+v] = %+v, want %+v from DefaultBindings", input, got, action)
+		}
+	}
+	for input, action := range NumpadBindings {
+		if got := ExtendedBindings[input]; got != action {
+			t.Errorf("ExtendedBindings[%+v] = %+v, want %+v from NumpadBindings", input, got, action)
+		}
+	}
+}
+
+func TestHandleEventScrollThreshold(t *testing.T) {
+	small := wheel(0.25, 0, sdl.MOUSEWHEEL_NORMAL)
+	tests := []struct {
+		name      string
+		threshold float32
+		rounds    [][]sdl.Event // events of each round of input
+		want      [][]string    // input reported in each round
+	}{
+		{
+			"below threshold",
+			1,
+			[][]sdl.Event{{small, small}},
+			[][]string{nil},
+		},
+		{
+			"reaches threshold",
+			1,
+			[][]sdl.Event{{small, small, small, small}},
+			[][]string{{"scroll (1, 0)"}},
+		},
+		{
+			"across rounds",
+			0.5,
+			[][]sdl.Event{{small}, {small}, {small}},
+			[][]string{nil, {"scroll (0.5, 0)"}, nil},
+		},
+		{
+			"either axis",
+			0.5,
+			[][]sdl.Event{{small, wheel(0, -0.5, sdl.MOUSEWHEEL_NORMAL)}},
+			[][]string{{"scroll (0.25, -0.5)"}},
+		},
+		{
+			"opposite directions cancel",
+			0.5,
+			[][]sdl.Event{{small, wheel(-0.25, 0, sdl.MOUSEWHEEL_NORMAL), small}},
+			[][]string{nil},
+		},
+		{
+			"zero threshold",
+			0,
+			[][]sdl.Event{{small}},
+			[][]string{{"scroll (0.25, 0)"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := NewEventHandler(DefaultBindings)
+			if err := h.SetScrollOpts(ScrollOpts{Threshold: test.threshold}); err != nil {
+				t.Fatal(err)
+			}
+			for i, events := range test.rounds {
+				got := handleEvents(t, &h, events...)
+				if !reflect.DeepEqual(got, test.want[i]) {
+					t.Errorf("round %d: got %q, want %q", i, got, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSetScrollOptsNegative(t *testing.T) {
+	h := NewEventHandler(DefaultBindings)
+	if err := h.SetScrollOpts(ScrollOpts{Threshold: -1}); err == nil {
+		t.Error("SetScrollOpts succeeded with a negative Threshold")
+	}
+	if h.ScrollOpts() != DefaultScrollOpts {
+		t.Errorf("SetScrollOpts changed the options to %+v despite failing", h.ScrollOpts())
+	}
+}
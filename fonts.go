@@ -0,0 +1,445 @@
+package nksdl
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"time"
+	"unsafe"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	// minFontSize and maxFontSize bound the sizes set by SetFontSize.
+	minFontSize = 6
+	maxFontSize = 96
+	// fontRebuildDelay is how long the font size must stay unchanged after a
+	// call to SetFontSize before the font atlas is rebuilt.
+	fontRebuildDelay = 250 * time.Millisecond
+)
+
+// namedFont is a font registered with AddFont.
+type namedFont struct {
+	opts      FontOpts
+	font      *nk.Font
+	largeFont *nk.Font // used instead of font at large render scales
+}
+
+// AddFont registers a font under the given name, in addition to the default
+// font created by NkDriver, for use with WithFont. Like the default font, each
+// named font is baked into the font atlas at both normal and large sizes, with
+// the large size used at high render scales. Only the Path and Size of opts
+// are used. AddFont must be called before Init.
+func (d *Driver) AddFont(name string, opts FontOpts) error {
+	if d.context != nil {
+		return errors.New("fonts cannot be added after Init")
+	} else if name == "" {
+		return errors.New("font name is empty")
+	} else if _, exists := d.namedFonts[name]; exists {
+		return fmt.Errorf("font %q is already registered", name)
+	}
+	if d.namedFonts == nil {
+		d.namedFonts = make(map[string]*namedFont)
+	}
+	d.namedFonts[name] = &namedFont{opts: opts}
+	return nil
+}
+
+// WithFont sets the font registered under the given name (see AddFont) as the
+// font of the Nuklear context, calls fn, and then restores the font that was
+// set before, even if fn panics. Calls to WithFont can be nested, and restore
+// their fonts in LIFO order. WithFont should only be called between FrameStart
+// and FrameEnd, and fails without calling fn before Init.
+func (d *Driver) WithFont(name string, fn func()) error {
+	if d.context == nil {
+		return errors.New("fonts cannot be used before Init")
+	}
+	font, ok := d.namedFonts[name]
+	if !ok {
+		return fmt.Errorf("font %q is not registered", name)
+	}
+	prevFont := d.currentFont
+	defer d.setFont(prevFont)
+	if d.largeFonts {
+		d.setFont(font.largeFont.Handle())
+	} else {
+		d.setFont(font.font.Handle())
+	}
+	fn()
+	return nil
+}
+
+// setDefaultFont sets the default font of the Nuklear context, at the size
+// suited to the render scale of the current frame.
+func (d *Driver) setDefaultFont() {
+	if d.largeFonts {
+		d.setFont(d.largeFont.Handle())
+	} else {
+		d.setFont(d.font.Handle())
+	}
+}
+
+// setFont sets the font of the Nuklear context.
+func (d *Driver) setFont(font *nk.UserFont) {
+	d.context.StyleSetFont(font)
+	for _, l := range d.layers {
+		l.context.StyleSetFont(font)
+	}
+	d.currentFont = font
+}
+
+// SetFontSize changes the size of the font registered under the given name (see
+// AddFont), e.g. to apply a user preference for larger text independently of
+// the render scale. The size is clamped to the range [6, 96]. Changing the
+// size requires rebaking the whole font atlas, so it takes effect in the first
+// call to FrameStart after the size has gone unchanged for 250 ms; rapid
+// changes, such as those made while dragging a slider, are coalesced into a
+// single rebuild. SetFontSize must be called after Init.
+func (d *Driver) SetFontSize(name string, size float32) error {
+	if d.context == nil {
+		return errors.New("font size cannot be set before Init")
+	}
+	font, ok := d.namedFonts[name]
+	if !ok {
+		return fmt.Errorf("font %q is not registered", name)
+	}
+	if size < minFontSize {
+		size = minFontSize
+	} else if size > maxFontSize {
+		size = maxFontSize
+	}
+	if size != font.opts.Size {
+		font.opts.Size = size
+		d.fontChanged = d.now()
+	}
+	return nil
+}
+
+// maybeRebuildFonts rebuilds the font atlas if a font size has changed and has
+// since settled.
+func (d *Driver) maybeRebuildFonts() error {
+	if d.fontChanged.IsZero() || d.fontLoad != nil || d.now().Sub(d.fontChanged) < fontRebuildDelay {
+		return nil
+	}
+	d.fontChanged = time.Time{}
+	if err := d.rebuildFonts(); err != nil {
+		return fmt.Errorf("rebuilding fonts: %w", err)
+	}
+	return nil
+}
+
+// fontBuild is a baked font atlas with its fonts, whose image has not yet
+// been uploaded to a texture.
+type fontBuild struct {
+	atlas         *nk.FontAtlas
+	font          *nk.Font
+	largeFont     *nk.Font
+	namedFonts    map[string]namedFont
+	image         []byte
+	width, height int32
+}
+
+// buildFonts creates a font atlas with the default font of nkDriver and the
+// named fonts given by namedOpts, each at normal and large sizes, and bakes
+// it. buildFonts does not call SDL, so it can run on any goroutine.
+func buildFonts(nkDriver NkDriver, namedOpts map[string]FontOpts) (b *fontBuild, err error) {
+	b = &fontBuild{namedFonts: make(map[string]namedFont, len(namedOpts))}
+	defer func() {
+		if err != nil {
+			b.atlas.Free()
+		}
+	}()
+	if b.atlas, err = nkDriver.CreateFontAtlas(); err != nil {
+		return nil, fmt.Errorf("creating font atlas: %w", err)
+	}
+	if b.font, err = nkDriver.CreateFont(b.atlas, 1); err != nil {
+		return nil, fmt.Errorf("creating font: %w", err)
+	}
+	if b.largeFont, err = nkDriver.CreateFont(b.atlas, 2); err != nil {
+		return nil, fmt.Errorf("creating large font: %w", err)
+	}
+	for name, opts := range namedOpts {
+		font := namedFont{opts: opts}
+		if font.font, err = addFont(b.atlas, opts, 1); err != nil {
+			return nil, fmt.Errorf("creating font %q: %w", name, err)
+		}
+		if font.largeFont, err = addFont(b.atlas, opts, 2); err != nil {
+			return nil, fmt.Errorf("creating large font %q: %w", name, err)
+		}
+		b.namedFonts[name] = font
+	}
+	if b.image, b.width, b.height, err = nkDriver.BakeFontAtlas(b.atlas); err != nil {
+		return nil, fmt.Errorf("baking font: %w", err)
+	}
+	return b, nil
+}
+
+// namedFontOpts returns the options of the fonts registered with AddFont.
+func (d *Driver) namedFontOpts() map[string]FontOpts {
+	opts := make(map[string]FontOpts, len(d.namedFonts))
+	for name, font := range d.namedFonts {
+		opts[name] = font.opts
+	}
+	return opts
+}
+
+// rebuildFonts creates and bakes a font atlas with all fonts at their current
+// sizes, and puts it in use. If rebuilding fails, the old atlas stays in use.
+func (d *Driver) rebuildFonts() error {
+	b, err := buildFonts(d.nkDriver, d.namedFontOpts())
+	if err != nil {
+		return err
+	}
+	return d.installFonts(b)
+}
+
+// centerNullTexture moves the UV of null, in an atlas image of the given size,
+// to the center of its white block. Nuklear points the null texture at the
+// center of the top-left texel of a 2x2 block of opaque white texels, so a
+// slightly inexact sample can blend in the transparent texel to its left or
+// above it, tinting solid fills at some scales. The center of the block is
+// safe by half a texel.
+func centerNullTexture(null nk.DrawNullTexture, width, height int32) nk.DrawNullTexture {
+	null.UV.X += 0.5 / float32(width)
+	null.UV.Y += 0.5 / float32(height)
+	return null
+}
+
+// installFonts uploads the image of b to a texture and replaces the font atlas
+// in use, and its fonts, with those of b. The old atlas and its texture are
+// freed. If the upload fails, b is freed and the old atlas stays in use.
+func (d *Driver) installFonts(b *fontBuild) error {
+	tex, err := d.createFontTexture(b.image, b.width, b.height)
+	if err != nil {
+		b.atlas.Free()
+		return err
+	}
+	atlasNull := b.atlas.End(TextureHandle(tex))
+	b.atlas.Cleanup()
+	atlasNull = centerNullTexture(atlasNull, b.width, b.height)
+	halveHeight(b.largeFont)
+	oldAtlas, oldTex := d.atlas, d.fontTex
+	d.atlas, d.font, d.largeFont = b.atlas, b.font, b.largeFont
+	d.fontTex, d.fontTexW, d.fontTexH = tex, b.width, b.height
+	for name, font := range b.namedFonts {
+		halveHeight(font.largeFont)
+		// keep the options, which SetFontSize may have changed since b was built
+		d.namedFonts[name].font = font.font
+		d.namedFonts[name].largeFont = font.largeFont
+	}
+	d.atlasNull = atlasNull
+	if d.nullTex == nil {
+		d.null = atlasNull
+	}
+	d.rebuildConvertConfig()
+	// the context must not be left pointing into the old atlas once it is freed
+	d.setDefaultFont()
+	oldAtlas.Free()
+	if oldTex != nil {
+		if err := oldTex.Destroy(); err != nil {
+			return fmt.Errorf("destroying old font texture: %w", err)
+		}
+	}
+	return nil
+}
+
+// createFontTexture creates a texture with the given image of a baked font
+// atlas.
+func (d *Driver) createFontTexture(image []byte, width, height int32) (tex *sdl.Texture, err error) {
+	info, err := d.renderer.GetInfo()
+	if err != nil {
+		return nil, fmt.Errorf("getting SDL renderer info: %w", err)
+	}
+	if info.MaxTextureWidth > 0 && width > info.MaxTextureWidth ||
+		info.MaxTextureHeight > 0 && height > info.MaxTextureHeight {
+		return nil, fmt.Errorf("baked font atlas (%dx%d) exceeds maximum texture size (%dx%d)",
+			width, height, info.MaxTextureWidth, info.MaxTextureHeight)
+	}
+	tex, err = d.renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STATIC, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("creating font texture: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tex.Destroy()
+		}
+	}()
+	if err = tex.Update(nil, image, int(4*width)); err != nil {
+		return nil, fmt.Errorf("uploading font atlas to texture: %w", err)
+	}
+	if err = tex.SetBlendMode(sdl.BLENDMODE_BLEND); err != nil {
+		return nil, fmt.Errorf("setting texture blend mode: %w", err)
+	}
+	return tex, nil
+}
+
+// loadingFontSize is the size of the built-in font used while fonts are
+// loaded in the background.
+const loadingFontSize = 13
+
+// fontLoadResult is the result of loading fonts in the background.
+type fontLoadResult struct {
+	build *fontBuild
+	err   error
+}
+
+// SetAsyncFontLoading sets whether Init bakes the font atlas in the background
+// instead of blocking until it is done, which can take hundreds of milliseconds
+// for fonts with many glyphs, e.g. CJK fonts. Until the fonts are ready (see
+// FontsReady), every font, including those registered with AddFont, is drawn
+// with Nuklear's small built-in font, so the application can show a loading
+// state without waiting. SetAsyncFontLoading must be called before Init.
+//
+// Only baking runs in the background: the CreateFontAtlas, CreateFont, and
+// BakeFontAtlas methods of the NkDriver are called on another goroutine, and
+// must therefore not call SDL or touch any state shared with the main thread.
+// Font files are also read on that goroutine. SDL textures must be created on
+// the main thread, so the baked atlas is uploaded by the first call to
+// FrameStart after baking finishes. While fonts are loading, FrameStart does
+// not lower the frame rate for idleness or lack of focus.
+func (d *Driver) SetAsyncFontLoading(async bool) error {
+	if d.context != nil {
+		return errors.New("async font loading cannot be set after Init")
+	}
+	d.asyncFonts = async
+	return nil
+}
+
+// FontsReady returns true once the fonts are in use, i.e. after Init, unless
+// fonts are loading in the background (see SetAsyncFontLoading), in which case
+// it returns false until the FrameStart that puts the loaded fonts in use.
+func (d *Driver) FontsReady() bool {
+	return d.context != nil && d.fontLoad == nil
+}
+
+// OnFontsReady sets a callback to be invoked when fonts loaded in the
+// background are put in use (see SetAsyncFontLoading). The callback is invoked
+// from FrameStart, before any input of the frame is reported to Nuklear. A nil
+// callback disables this behavior.
+func (d *Driver) OnFontsReady(callback func()) {
+	d.fontsReady = callback
+}
+
+// startFontLoad puts the built-in font in use for every font and starts
+// baking the actual fonts in the background.
+func (d *Driver) startFontLoad() error {
+	loadingOpts := FontOpts{Size: loadingFontSize}
+	namedOpts := d.namedFontOpts()
+	loadingNamedOpts := make(map[string]FontOpts, len(namedOpts))
+	for name := range namedOpts {
+		loadingNamedOpts[name] = loadingOpts
+	}
+	b, err := buildFonts(&DefaultNkDriver{Font: loadingOpts}, loadingNamedOpts)
+	if err != nil {
+		return fmt.Errorf("building loading font: %w", err)
+	}
+	if err := d.installFonts(b); err != nil {
+		return fmt.Errorf("installing loading font: %w", err)
+	}
+	fontLoad := make(chan fontLoadResult, 1)
+	d.fontLoad = fontLoad
+	nkDriver := d.nkDriver
+	go func() {
+		b, err := buildFonts(nkDriver, namedOpts)
+		fontLoad <- fontLoadResult{build: b, err: err}
+	}()
+	return nil
+}
+
+// finishFontLoad puts the fonts loaded in the background in use, if they are
+// ready.
+func (d *Driver) finishFontLoad() error {
+	if d.fontLoad == nil {
+		return nil
+	}
+	select {
+	case result := <-d.fontLoad:
+		d.fontLoad = nil
+		if result.err != nil {
+			return fmt.Errorf("loading fonts: %w", result.err)
+		}
+		if err := d.installFonts(result.build); err != nil {
+			return fmt.Errorf("loading fonts: %w", err)
+		}
+		if d.fontsReady != nil {
+			d.fontsReady()
+		}
+	default:
+	}
+	return nil
+}
+
+// cancelFontLoad waits for fonts loading in the background, if any, and frees
+// them.
+func (d *Driver) cancelFontLoad() {
+	if d.fontLoad == nil {
+		return
+	}
+	if result := <-d.fontLoad; result.build != nil {
+		result.build.atlas.Free()
+	}
+	d.fontLoad = nil
+}
+
+// FontAtlasImage returns the baked font atlas as an *image.NRGBA, e.g. to
+// inspect the packing of glyphs when debugging missing or garbled text. The
+// CPU-side copy of the atlas is discarded once it is uploaded, so the image is
+// read back from the font texture, by copying it to a temporary render target
+// and reading the pixels of that. This fails if the renderer does not support
+// render targets. FontAtlasImage must be called after Init and not between
+// FrameStart and FrameEnd; while fonts are loading in the background (see
+// SetAsyncFontLoading), it returns the atlas of the built-in loading font.
+func (d *Driver) FontAtlasImage() (img image.Image, err error) {
+	if !d.initialized {
+		return nil, ErrNotInitialized
+	} else if !d.renderer.RenderTargetSupported() {
+		return nil, errors.New("renderer does not support render targets")
+	}
+	width, height := d.fontTexW, d.fontTexH
+	target, err := d.renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_TARGET, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("creating render target: %w", err)
+	}
+	defer func() {
+		if err2 := target.Destroy(); err2 != nil && err == nil {
+			err = fmt.Errorf("destroying render target: %w", err2)
+		}
+	}()
+	oldTarget := d.renderer.GetRenderTarget()
+	if err := d.renderer.SetRenderTarget(target); err != nil {
+		return nil, fmt.Errorf("setting render target: %w", err)
+	}
+	defer func() {
+		if err2 := d.renderer.SetRenderTarget(oldTarget); err2 != nil && err == nil {
+			err = fmt.Errorf("restoring render target: %w", err2)
+		}
+	}()
+	// copy the texels exactly, rather than blending them onto the target
+	if err := d.fontTex.SetBlendMode(sdl.BLENDMODE_NONE); err != nil {
+		return nil, fmt.Errorf("setting texture blend mode: %w", err)
+	}
+	defer func() {
+		if err2 := d.fontTex.SetBlendMode(sdl.BLENDMODE_BLEND); err2 != nil && err == nil {
+			err = fmt.Errorf("restoring texture blend mode: %w", err2)
+		}
+	}()
+	if err := d.renderer.Copy(d.fontTex, nil, nil); err != nil {
+		return nil, fmt.Errorf("copying font texture: %w", err)
+	}
+	nrgba := image.NewNRGBA(image.Rect(0, 0, int(width), int(height)))
+	if len(nrgba.Pix) != 0 {
+		if err := d.renderer.ReadPixels(nil, uint32(sdl.PIXELFORMAT_RGBA32), unsafe.Pointer(&nrgba.Pix[0]), nrgba.Stride); err != nil {
+			return nil, fmt.Errorf("reading pixels: %w", err)
+		}
+	}
+	return nrgba, nil
+}
+
+// halveHeight halves the height of a large font, which is baked at twice the
+// size of the normal font, so that it is laid out at the same size.
+func halveHeight(font *nk.Font) {
+	handle := font.Handle()
+	handle.SetHeight(handle.Height() / 2)
+}
@@ -0,0 +1,88 @@
+package nksdl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kbolino/go-nk"
+)
+
+func TestWithFontBeforeInit(t *testing.T) {
+	d := newUninitializedDriver()
+	if err := d.AddFont("big", FontOpts{Size: 20}); err != nil {
+		t.Fatal(err)
+	}
+	called := false
+	if err := d.WithFont("big", func() { called = true }); err == nil {
+		t.Error("WithFont succeeded before Init")
+	}
+	if called {
+		t.Error("WithFont called fn before Init")
+	}
+}
+
+func TestCenterNullTexture(t *testing.T) {
+	b, err := buildFonts(&DefaultNkDriver{Font: FontOpts{Size: 13}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.atlas.Free()
+	// End frees the baked image
+	image := append([]byte(nil), b.image...)
+	null := centerNullTexture(b.atlas.End(TextureHandle(nil)), b.width, b.height)
+	// opaque reports whether the texel at (x, y) of the RGBA32 image is opaque
+	// white; texels outside of the image are not, so that the sample does not
+	// depend on how the renderer clamps texture coordinates
+	opaque := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= int(b.width) || y >= int(b.height) {
+			return false
+		}
+		i := 4 * (y*int(b.width) + x)
+		for _, c := range image[i : i+4] {
+			if c != 255 {
+				return false
+			}
+		}
+		return true
+	}
+	// a linear sample blends the texels either side of the sample point, so
+	// all of them must be opaque white even when the point is a little off
+	for _, offset := range []float64{-0.25, 0, 0.25} {
+		x := float64(null.UV.X)*float64(b.width) - 0.5 + offset
+		y := float64(null.UV.Y)*float64(b.height) - 0.5 + offset
+		for _, tx := range []int{int(math.Floor(x)), int(math.Ceil(x))} {
+			for _, ty := range []int{int(math.Floor(y)), int(math.Ceil(y))} {
+				if !opaque(tx, ty) {
+					t.Errorf("sampling at offset %g blends in texel (%d, %d), which is not opaque white", offset, tx, ty)
+				}
+			}
+		}
+	}
+}
+
+func TestRebuildFontsSwitchesContextFont(t *testing.T) {
+	td := newTestDriver(t)
+	if err := td.Frame(func(nkc *nk.Context) {}); err != nil {
+		t.Fatal(err)
+	}
+	old := td.currentFont
+	if err := td.rebuildFonts(); err != nil {
+		t.Fatal(err)
+	}
+	if td.currentFont == old {
+		t.Fatal("context still uses the font of the freed atlas")
+	}
+	if want := td.font.Handle(); td.currentFont != want {
+		t.Errorf("context uses font %p, want the new default font %p", td.currentFont, want)
+	}
+	// the new font must be usable by the next frame
+	if err := td.Frame(func(nkc *nk.Context) {
+		if nkc.Begin("test", &nk.Rect{W: 200, H: 100}, 0) {
+			nkc.LayoutRowDynamic(20, 1)
+			nkc.Text("text", nk.TextLeft)
+		}
+		nkc.End()
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+package nksdl
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// hotkey is a registered hotkey.
+type hotkey struct {
+	input    KeyInput
+	callback func()
+}
+
+// RegisterHotkey registers a callback to be invoked whenever the key given by
+// input is pressed, e.g. Ctrl+S to save. As with bindings, a generic modifier
+// like sdl.KMOD_CTRL matches either its left or right variant, while a specific
+// one like sdl.KMOD_LCTRL matches only itself; lock keys like NumLock are
+// ignored. A hotkey does not fire while the user is typing into an edit widget
+// reported to TrackEdit, if the key is bound to a Nuklear action (e.g. Ctrl+A)
+// or has no modifiers besides Shift. Callbacks are invoked from FrameStart,
+// after Nuklear has handled the key event and before the event is passed to
+// the EventListener. Registering a hotkey again replaces its callback.
+func (d *Driver) RegisterHotkey(input KeyInput, callback func()) {
+	for i := range d.hotkeys {
+		if d.hotkeys[i].input == input {
+			d.hotkeys[i].callback = callback
+			return
+		}
+	}
+	d.hotkeys = append(d.hotkeys, hotkey{input, callback})
+}
+
+// UnregisterHotkey removes the hotkey registered for input, if any.
+func (d *Driver) UnregisterHotkey(input KeyInput) {
+	for i := range d.hotkeys {
+		if d.hotkeys[i].input == input {
+			d.hotkeys = append(d.hotkeys[:i], d.hotkeys[i+1:]...)
+			return
+		}
+	}
+}
+
+// handleHotkey invokes the callback of the first registered hotkey matching
+// event, if the event is a key press which can fire hotkeys.
+func (d *Driver) handleHotkey(event *sdl.KeyboardEvent, usedByNuklear bool) {
+	if len(d.hotkeys) == 0 || event.State != sdl.PRESSED || event.Repeat != 0 {
+		return
+	}
+	input := KeysymInput(event.Keysym)
+	input.Mod &^= lockMods
+	if d.textInput.editing && (usedByNuklear || input.Mod&^sdl.KMOD_SHIFT == 0) {
+		return
+	}
+	for _, hotkey := range d.hotkeys {
+		if hotkeyMatches(hotkey.input, input) {
+			hotkey.callback()
+			return
+		}
+	}
+}
+
+// hotkeyMatches returns true if the registered hotkey matches input.
+func hotkeyMatches(hotkey, input KeyInput) bool {
+	if hotkey.Code != input.Code {
+		return false
+	}
+	return modMatches(hotkey.Mod, input.Mod, sdl.KMOD_CTRL) &&
+		modMatches(hotkey.Mod, input.Mod, sdl.KMOD_SHIFT) &&
+		modMatches(hotkey.Mod, input.Mod, sdl.KMOD_ALT) &&
+		modMatches(hotkey.Mod, input.Mod, sdl.KMOD_GUI)
+}
+
+// modMatches implements hotkeyMatches for a single modifier key, where both is
+// its generic variant.
+func modMatches(hotkeyMod, inputMod, both sdl.Keymod) bool {
+	hotkeyMod &= both
+	inputMod &= both
+	if hotkeyMod == both {
+		return inputMod != 0
+	}
+	return hotkeyMod == inputMod
+}
@@ -0,0 +1,48 @@
+package nksdl
+
+import (
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestHotkeyMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		hotkey KeyInput
+		input  KeyInput
+		want   bool
+	}{
+		{"plain", KeyInput{Code: sdl.K_F5}, KeyInput{Code: sdl.K_F5}, true},
+		{"other key", KeyInput{Code: sdl.K_F5}, KeyInput{Code: sdl.K_F6}, false},
+		{"extra modifier", KeyInput{Code: sdl.K_F5}, KeyInput{Code: sdl.K_F5, Mod: sdl.KMOD_LSHIFT}, false},
+		{"missing modifier", KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_CTRL}, KeyInput{Code: sdl.K_s}, false},
+		{"generic left", KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_CTRL}, KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_LCTRL}, true},
+		{"generic right", KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_CTRL}, KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_RCTRL}, true},
+		{"generic both", KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_CTRL}, KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_CTRL}, true},
+		{"specific left", KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_LALT}, KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_LALT}, true},
+		{"specific wrong side", KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_LALT}, KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_RALT}, false},
+		{"specific both sides", KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_LALT}, KeyInput{Code: sdl.K_s, Mod: sdl.KMOD_ALT}, false},
+		{
+			"several",
+			KeyInput{Code: sdl.K_z, Mod: sdl.KMOD_CTRL | sdl.KMOD_SHIFT},
+			KeyInput{Code: sdl.K_z, Mod: sdl.KMOD_RCTRL | sdl.KMOD_LSHIFT},
+			true,
+		},
+		{
+			"several missing one",
+			KeyInput{Code: sdl.K_z, Mod: sdl.KMOD_CTRL | sdl.KMOD_SHIFT},
+			KeyInput{Code: sdl.K_z, Mod: sdl.KMOD_RCTRL},
+			false,
+		},
+		{"gui", KeyInput{Code: sdl.K_q, Mod: sdl.KMOD_GUI}, KeyInput{Code: sdl.K_q, Mod: sdl.KMOD_RGUI}, true},
+		{"mode modifier ignored", KeyInput{Code: sdl.K_q}, KeyInput{Code: sdl.K_q, Mod: sdl.KMOD_MODE}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := hotkeyMatches(test.hotkey, test.input); got != test.want {
+				t.Errorf("hotkeyMatches(%+v, %+v) = %t, want %t", test.hotkey, test.input, got, test.want)
+			}
+		})
+	}
+}
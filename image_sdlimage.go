@@ -0,0 +1,30 @@
+//go:build sdl_image
+
+package nksdl
+
+import (
+	"fmt"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/img"
+)
+
+// LoadImage decodes the image file at path with SDL_image, which supports PNG,
+// JPEG, and many other formats, and uploads it to a texture owned by the
+// Driver, which is destroyed by Destroy or FreeImage. LoadImage must be called
+// after Init.
+//
+// LoadImage is only available when building with the sdl_image build tag, since
+// it requires the SDL_image library in addition to SDL itself.
+func (d *Driver) LoadImage(path string) (nk.Image, error) {
+	tex, err := img.LoadTexture(d.renderer, path)
+	if err != nil {
+		return nk.Image{}, fmt.Errorf("loading image from %q: %w", path, err)
+	}
+	image, err := d.registerImage(tex)
+	if err != nil {
+		tex.Destroy()
+		return nk.Image{}, fmt.Errorf("loading image from %q: %w", path, err)
+	}
+	return image, nil
+}
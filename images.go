@@ -0,0 +1,52 @@
+package nksdl
+
+import (
+	"fmt"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// registerImage registers tex, which is owned by d from then on, for
+// destruction by Destroy, and returns an image of the whole texture for use
+// with Nuklear.
+func (d *Driver) registerImage(tex *sdl.Texture) (nk.Image, error) {
+	_, _, width, height, err := tex.Query()
+	if err != nil {
+		return nk.Image{}, fmt.Errorf("querying texture: %w", err)
+	}
+	if width > 0xFFFF || height > 0xFFFF {
+		return nk.Image{}, fmt.Errorf("texture size %dx%d is too large for an image", width, height)
+	}
+	d.images = append(d.images, tex)
+	return nk.Image{
+		Handle: TextureHandle(tex),
+		W:      uint16(width),
+		H:      uint16(height),
+		Region: [4]uint16{0, 0, uint16(width), uint16(height)},
+	}, nil
+}
+
+// FreeImage destroys the texture of an image loaded by the Driver, e.g. by
+// LoadImage, before Destroy does. The image must not be used afterward.
+func (d *Driver) FreeImage(image nk.Image) error {
+	tex := HandleTexture(image.Handle)
+	for i := range d.images {
+		if d.images[i] == tex {
+			d.images = append(d.images[:i], d.images[i+1:]...)
+			return tex.Destroy()
+		}
+	}
+	return fmt.Errorf("image was not loaded by this driver")
+}
+
+// destroyImages destroys the textures of all images loaded by the Driver.
+func (d *Driver) destroyImages() (err error) {
+	for _, tex := range d.images {
+		if err2 := tex.Destroy(); err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	d.images = nil
+	return err
+}
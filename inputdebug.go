@@ -0,0 +1,133 @@
+package nksdl
+
+import (
+	"fmt"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+var keyNames = map[nk.Key]string{
+	nk.KeyNone:            "None",
+	nk.KeyShift:           "Shift",
+	nk.KeyCtrl:            "Ctrl",
+	nk.KeyDel:             "Del",
+	nk.KeyEnter:           "Enter",
+	nk.KeyTab:             "Tab",
+	nk.KeyBackspace:       "Backspace",
+	nk.KeyCopy:            "Copy",
+	nk.KeyCut:             "Cut",
+	nk.KeyPaste:           "Paste",
+	nk.KeyUp:              "Up",
+	nk.KeyDown:            "Down",
+	nk.KeyLeft:            "Left",
+	nk.KeyRight:           "Right",
+	nk.KeyTextInsertMode:  "TextInsertMode",
+	nk.KeyTextReplaceMode: "TextReplaceMode",
+	nk.KeyTextResetMode:   "TextResetMode",
+	nk.KeyTextLineStart:   "TextLineStart",
+	nk.KeyTextLineEnd:     "TextLineEnd",
+	nk.KeyTextStart:       "TextStart",
+	nk.KeyTextEnd:         "TextEnd",
+	nk.KeyTextUndo:        "TextUndo",
+	nk.KeyTextRedo:        "TextRedo",
+	nk.KeyTextSelectAll:   "TextSelectAll",
+	nk.KeyTextWordLeft:    "TextWordLeft",
+	nk.KeyTextWordRight:   "TextWordRight",
+	nk.KeyScrollStart:     "ScrollStart",
+	nk.KeyScrollEnd:       "ScrollEnd",
+	nk.KeyScrollDown:      "ScrollDown",
+	nk.KeyScrollUp:        "ScrollUp",
+}
+
+var buttonNames = map[nk.Button]string{
+	nk.ButtonLeft:   "Left",
+	nk.ButtonMiddle: "Middle",
+	nk.ButtonRight:  "Right",
+	nk.ButtonDouble: "Double",
+}
+
+func keyName(key nk.Key) string {
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+	return fmt.Sprintf("Key(%d)", key)
+}
+
+func buttonName(button nk.Button) string {
+	if name, ok := buttonNames[button]; ok {
+		return name
+	}
+	return fmt.Sprintf("Button(%d)", button)
+}
+
+func (d *Driver) InputDebug() bool {
+	return d.eventHandler.debug
+}
+
+// SetInputDebug sets whether every input reported to Nuklear is logged, as a
+// debug message in the SDL input category, along with the Nuklear actions that
+// key events are bound to. This is useful for diagnosing problems like buttons
+// that will not click or keys that appear stuck, but is too verbose to leave
+// on in production. Debug messages are only shown if their priority is enabled,
+// e.g. with sdl.LogSetPriority(sdl.LOG_CATEGORY_INPUT, sdl.LOG_PRIORITY_DEBUG).
+func (d *Driver) SetInputDebug(debug bool) {
+	d.eventHandler.debug = debug
+}
+
+func (h *EventHandler) logInput(format string, args ...interface{}) {
+	if h.trace != nil {
+		h.trace(fmt.Sprintf(format, args...))
+	}
+	sdl.LogDebug(sdl.LOG_CATEGORY_INPUT, "nksdl input: "+format, args...)
+}
+
+func (h *EventHandler) inputMotion(nkc *nk.Context, x, y int32) {
+	if h.debug {
+		h.logInput("motion (%d, %d)", x, y)
+	}
+	nkc.InputMotion(x, y)
+	for _, mirror := range h.mirrors {
+		mirror.InputMotion(x, y)
+	}
+}
+
+func (h *EventHandler) inputButton(nkc *nk.Context, button nk.Button, x, y int32, down bool) {
+	if h.debug {
+		h.logInput("button %s down=%t at (%d, %d)", buttonName(button), down, x, y)
+	}
+	nkc.InputButton(button, x, y, down)
+	for _, mirror := range h.mirrors {
+		mirror.InputButton(button, x, y, down)
+	}
+}
+
+func (h *EventHandler) inputScroll(nkc *nk.Context, x, y float32) {
+	if h.debug {
+		h.logInput("scroll (%g, %g)", x, y)
+	}
+	nkc.InputScroll(x, y)
+	for _, mirror := range h.mirrors {
+		mirror.InputScroll(x, y)
+	}
+}
+
+func (h *EventHandler) inputKey(nkc *nk.Context, key nk.Key, down bool) {
+	if h.debug {
+		h.logInput("key %s down=%t", keyName(key), down)
+	}
+	nkc.InputKey(key, down)
+	for _, mirror := range h.mirrors {
+		mirror.InputKey(key, down)
+	}
+}
+
+func (h *EventHandler) inputUnicode(nkc *nk.Context, r rune) {
+	if h.debug {
+		h.logInput("unicode %q", r)
+	}
+	nkc.InputUnicode(r)
+	for _, mirror := range h.mirrors {
+		mirror.InputUnicode(r)
+	}
+}
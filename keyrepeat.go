@@ -0,0 +1,109 @@
+package nksdl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// KeyRepeatOpts sets options for key repeat managed by the EventHandler, which
+// repeats the actions of a held key at the same rate on every platform,
+// regardless of whether and how fast the operating system repeats keys. While
+// a key's actions are repeated this way, key repeat events from the operating
+// system are ignored for it. Only the most recently pressed key repeats, and
+// it stops repeating when it is released, when another key is pressed, or when
+// the window loses focus.
+type KeyRepeatOpts struct {
+	// Delay is how long a key must be held before its actions repeat. A Delay
+	// of zero disables managed key repeat, leaving it to the operating system.
+	Delay time.Duration
+	// Interval is the time between repeats. It must be positive if Delay is
+	// non-zero. Actions repeat at most once per frame.
+	Interval time.Duration
+	// AllActions opts every binding into managed key repeat. Otherwise, only
+	// bindings with KeyAction.Repeat set repeat this way.
+	AllActions bool
+}
+
+// applies returns true if managed key repeat applies to action.
+func (opts KeyRepeatOpts) applies(action KeyAction) bool {
+	return opts.Delay != 0 && (opts.AllActions || action.Repeat)
+}
+
+// heldKey is a key whose actions are repeated by the EventHandler.
+type heldKey struct {
+	sym    sdl.Keycode
+	action KeyAction
+	next   time.Time // time of the next repeat, zero until the first frame
+	active bool
+}
+
+func (h *EventHandler) KeyRepeatOpts() KeyRepeatOpts {
+	return h.keyRepeat
+}
+
+// SetKeyRepeatOpts sets the options for managed key repeat. Managed key repeat
+// is off by default.
+func (h *EventHandler) SetKeyRepeatOpts(opts KeyRepeatOpts) error {
+	if opts.Delay < 0 {
+		return fmt.Errorf("key repeat Delay(%s) is negative", opts.Delay)
+	} else if opts.Delay > 0 && opts.Interval <= 0 {
+		return fmt.Errorf("key repeat Interval(%s) is not positive", opts.Interval)
+	}
+	h.keyRepeat = opts
+	h.held = heldKey{}
+	return nil
+}
+
+// trackHeldKey updates the held key in response to a key event bound to
+// action. It returns true if the event is a key repeat from the operating
+// system which must be ignored, because the key repeats under management.
+func (h *EventHandler) trackHeldKey(e *sdl.KeyboardEvent, action KeyAction) bool {
+	if e.State != sdl.PRESSED {
+		if h.held.active && e.Keysym.Sym == h.held.sym {
+			h.held = heldKey{}
+		}
+		return false
+	} else if e.Repeat != 0 {
+		return h.keyRepeat.applies(action) && action.Key1 != nk.KeyNone
+	}
+	h.held = heldKey{}
+	if h.keyRepeat.applies(action) && action.Key1 != nk.KeyNone {
+		h.held = heldKey{sym: e.Keysym.Sym, action: action, active: true}
+	}
+	return false
+}
+
+// RepeatKeys repeats the actions of the held key, if managed key repeat is
+// enabled (see SetKeyRepeatOpts) and a repeat is due at time now. It must be
+// called once per frame between nkc.InputBegin and nkc.InputEnd, after the
+// events of the frame have been handled, which Driver.FrameStart does. It
+// returns true if any actions were repeated.
+func (h *EventHandler) RepeatKeys(nkc *nk.Context, now time.Time) bool {
+	if !h.held.active {
+		return false
+	} else if h.held.next.IsZero() {
+		h.held.next = now.Add(h.keyRepeat.Delay)
+		return false
+	} else if now.Before(h.held.next) {
+		return false
+	}
+	h.inputKey(nkc, h.held.action.Key1, true)
+	if h.held.action.Key2 != nk.KeyNone {
+		h.inputKey(nkc, h.held.action.Key2, true)
+	}
+	h.held.next = h.held.next.Add(h.keyRepeat.Interval)
+	if h.held.next.Before(now) {
+		// the frame was too long to keep up, so repeat again next frame
+		h.held.next = now
+	}
+	return true
+}
+
+// releaseHeldKey stops repeating the held key, e.g. when the window loses
+// focus.
+func (h *EventHandler) releaseHeldKey() {
+	h.held = heldKey{}
+}
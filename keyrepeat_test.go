@@ -0,0 +1,167 @@
+package nksdl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// keyEvent returns an event for a press or release of the key sym, which is a
+// repeat from the operating system if repeat is true.
+func keyEvent(sym sdl.Keycode, pressed, repeat bool) *sdl.KeyboardEvent {
+	e := &sdl.KeyboardEvent{
+		Type:   sdl.KEYUP,
+		State:  sdl.RELEASED,
+		Keysym: sdl.Keysym{Sym: sym},
+	}
+	if pressed {
+		e.Type, e.State = sdl.KEYDOWN, sdl.PRESSED
+	}
+	if repeat {
+		e.Repeat = 1
+	}
+	return e
+}
+
+func TestSetKeyRepeatOpts(t *testing.T) {
+	tests := []struct {
+		opts  KeyRepeatOpts
+		valid bool
+	}{
+		{KeyRepeatOpts{}, true},
+		{KeyRepeatOpts{Delay: time.Second, Interval: time.Millisecond}, true},
+		{KeyRepeatOpts{Interval: -time.Millisecond}, true},
+		{KeyRepeatOpts{Delay: -time.Second, Interval: time.Millisecond}, false},
+		{KeyRepeatOpts{Delay: time.Second}, false},
+		{KeyRepeatOpts{Delay: time.Second, Interval: -time.Millisecond}, false},
+	}
+	for _, test := range tests {
+		h := NewEventHandler(DefaultBindings)
+		err := h.SetKeyRepeatOpts(test.opts)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("SetKeyRepeatOpts(%+v) returned %v, want valid=%t", test.opts, err, test.valid)
+		} else if !valid && h.KeyRepeatOpts() != (KeyRepeatOpts{}) {
+			t.Errorf("SetKeyRepeatOpts(%+v) changed the options to %+v despite failing", test.opts, h.KeyRepeatOpts())
+		}
+	}
+}
+
+func TestRepeatKeys(t *testing.T) {
+	const (
+		leftDown  = "key Left down=true"
+		leftUp    = "key Left down=false"
+		rightDown = "key Right down=true"
+	)
+	// each step either handles event or, if it is nil, repeats keys at time at
+	type step struct {
+		event sdl.Event
+		at    time.Duration
+		want  []string // input reported by the step, besides key event logs
+	}
+	press := func(sym sdl.Keycode, want ...string) step {
+		return step{event: keyEvent(sym, true, false), want: want}
+	}
+	osRepeat := func(sym sdl.Keycode, want ...string) step {
+		return step{event: keyEvent(sym, true, true), want: want}
+	}
+	release := func(sym sdl.Keycode, want ...string) step {
+		return step{event: keyEvent(sym, false, false), want: want}
+	}
+	frame := func(at time.Duration, want ...string) step {
+		return step{at: at, want: want}
+	}
+	tests := []struct {
+		name       string
+		allActions bool
+		steps      []step
+	}{
+		{"held", true, []step{
+			press(sdl.K_LEFT, leftDown),
+			frame(0),
+			frame(299 * time.Millisecond),
+			osRepeat(sdl.K_LEFT),
+			frame(300*time.Millisecond, leftDown),
+			frame(350 * time.Millisecond),
+			frame(400*time.Millisecond, leftDown),
+			release(sdl.K_LEFT, leftUp),
+			frame(500 * time.Millisecond),
+		}},
+		{"long frame", true, []step{
+			press(sdl.K_LEFT, leftDown),
+			frame(0),
+			frame(time.Second, leftDown),
+			frame(time.Second+time.Millisecond, leftDown),
+			frame(time.Second + 50*time.Millisecond),
+			frame(time.Second+101*time.Millisecond, leftDown),
+		}},
+		{"other key pressed", true, []step{
+			press(sdl.K_LEFT, leftDown),
+			frame(0),
+			press(sdl.K_RIGHT, rightDown),
+			frame(300 * time.Millisecond),
+			frame(600*time.Millisecond, rightDown),
+			release(sdl.K_LEFT, leftUp),
+			frame(700*time.Millisecond, rightDown),
+		}},
+		{"not opted in", false, []step{
+			press(sdl.K_LEFT, leftDown),
+			frame(0),
+			osRepeat(sdl.K_LEFT, leftDown),
+			frame(300 * time.Millisecond),
+		}},
+		{"unbound key", true, []step{
+			press(sdl.K_F1),
+			frame(0),
+			frame(300 * time.Millisecond),
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := NewEventHandler(DefaultBindings)
+			opts := KeyRepeatOpts{Delay: 300 * time.Millisecond, Interval: 100 * time.Millisecond, AllActions: test.allActions}
+			if err := h.SetKeyRepeatOpts(opts); err != nil {
+				t.Fatal(err)
+			}
+			start := time.Date(2022, 3, 25, 0, 0, 0, 0, time.UTC)
+			for i, step := range test.steps {
+				var got []string
+				if step.event != nil {
+					got = handleEvents(t, &h, step.event)
+					// drop the log of the key event itself
+					if len(got) > 0 {
+						got = got[1:]
+					}
+				} else {
+					got = traceInput(t, &h, func(nkc *nk.Context) {
+						h.RepeatKeys(nkc, start.Add(step.at))
+					})
+				}
+				if len(got) != len(step.want) || len(got) != 0 && !reflect.DeepEqual(got, step.want) {
+					t.Errorf("step %d: got %q, want %q", i, got, step.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReleaseHeldKey(t *testing.T) {
+	h := NewEventHandler(DefaultBindings)
+	if err := h.SetKeyRepeatOpts(KeyRepeatOpts{Delay: time.Millisecond, Interval: time.Millisecond, AllActions: true}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2022, 3, 25, 0, 0, 0, 0, time.UTC)
+	handleEvents(t, &h, keyEvent(sdl.K_LEFT, true, false))
+	traceInput(t, &h, func(nkc *nk.Context) {
+		h.RepeatKeys(nkc, start)
+	})
+	h.releaseHeldKey()
+	got := traceInput(t, &h, func(nkc *nk.Context) {
+		h.RepeatKeys(nkc, start.Add(time.Second))
+	})
+	if len(got) != 0 {
+		t.Errorf("key repeated after it was released: %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+package nksdl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kbolino/go-nk"
+)
+
+// layer is an additional Nuklear context rendered above the Driver's context.
+type layer struct {
+	context *nk.Context
+	input   bool // whether the layer receives input
+}
+
+// AddLayer creates an additional Nuklear context, with its own windows and
+// state, which is composited above the Driver's context and any layers added
+// before it, e.g. to keep a foreground modal independent of the rest of the
+// GUI, or a background HUD independent of a foreground GUI added as a later
+// layer. The Driver clears the layer, gathers its input, sets its default
+// font, and renders it along with its own context, so the application only
+// declares its GUI between FrameStart and FrameEnd as usual. A layer is
+// rendered after the whole of the layers below it, regardless of the order in
+// which the windows of different layers are declared.
+//
+// If input is true, the layer receives all the input that the Driver's
+// context receives; otherwise it receives none, which suits display-only
+// layers. Nuklear cannot tell which events a context consumed, so input is not
+// routed to the topmost layer under the cursor: every layer that receives
+// input sees every event, and a click on a window in one layer also reaches
+// any window beneath it in lower layers. Applications that need exclusive
+// input should give it to one layer at a time, e.g. by making the lower GUI
+// not interactive while a modal layer is shown. FrameInput and other input
+// state of the Driver describe its own context.
+//
+// AddLayer must be called after Init and outside of input gathering. The
+// layer is freed by RemoveLayer or Destroy.
+func (d *Driver) AddLayer(input bool) (*nk.Context, error) {
+	if !d.initialized {
+		return nil, ErrNotInitialized
+	} else if d.inputOpen {
+		return nil, errors.New("cannot add a layer while input is open")
+	}
+	nkc, err := d.nkDriver.CreateContext()
+	if err != nil {
+		return nil, fmt.Errorf("creating Nuklear context for layer: %w", err)
+	}
+	nkc.StyleSetFont(d.currentFont)
+	d.layers = append(d.layers, layer{context: nkc, input: input})
+	d.updateMirrors()
+	return nkc, nil
+}
+
+// RemoveLayer removes and frees a layer added by AddLayer. It must not be
+// called during a frame or while input is open.
+func (d *Driver) RemoveLayer(nkc *nk.Context) error {
+	if d.inputOpen || d.inFrame {
+		return errors.New("cannot remove a layer during a frame")
+	}
+	for i, l := range d.layers {
+		if l.context == nkc {
+			d.layers = append(d.layers[:i], d.layers[i+1:]...)
+			d.updateMirrors()
+			nkc.Free()
+			return nil
+		}
+	}
+	return errors.New("context is not a layer of the Driver")
+}
+
+// updateMirrors updates the contexts to which the EventHandler reports input
+// besides the Driver's context.
+func (d *Driver) updateMirrors() {
+	d.eventHandler.mirrors = d.eventHandler.mirrors[:0]
+	for _, l := range d.layers {
+		if l.input {
+			d.eventHandler.mirrors = append(d.eventHandler.mirrors, l.context)
+		}
+	}
+}
+
+// freeLayers frees all layers.
+func (d *Driver) freeLayers() {
+	for _, l := range d.layers {
+		l.context.Free()
+	}
+	d.layers = nil
+	d.eventHandler.mirrors = nil
+}
@@ -0,0 +1,160 @@
+package nksdl
+
+import (
+	"fmt"
+
+	"github.com/kbolino/go-nk"
+)
+
+// maxMeasureSize is the size of the window in which MeasureContent lays out
+// content, in any dimension which is not given. It is within the range of the
+// 16-bit coordinates of Nuklear's draw commands.
+const maxMeasureSize = 16384
+
+// measureWindowName is the name of the window of MeasureContent.
+const measureWindowName = "nksdl measure"
+
+// MeasureContent estimates the size of a window needed to show the content
+// declared by build without scrolling, e.g. to create a window at the right
+// size. It lays out the content in a hidden pass, in a private Nuklear context
+// with the current font, inside a window of the given width without a title,
+// border, or scrollbar, and returns the extent of everything drawn, including
+// the window padding. Nothing is shown, no input is received, and the Nuklear
+// context of the Driver is not touched, so MeasureContent can be called at any
+// time after Init, including during a frame.
+//
+// The result is an estimate. Dynamic layouts fill the width they are given, so
+// only the height is meaningful for them, and the width should be the width
+// the window will have; for static layouts, a width of 0 lays out the content
+// without limit, so that the width is meaningful too. Text is measured by the
+// width of its widget, not of the text itself. The title bar, border, and
+// scrollbars of the real window must be added, as must the effect of any style
+// or font set on the Driver's context within the frame, e.g. by WithFont,
+// since build receives the private context. The private context receives no
+// input, so content that depends on the state of widgets, e.g. an expanded
+// tree node, is measured in its initial state.
+//
+// Since Nuklear window bounds only take effect when the window is first
+// created, the usual pattern is to measure in one frame and begin the window
+// with the measured size in the same or a later frame. If the content changes,
+// the window keeps its old size until it is recreated, so the new size lags by
+// at least a frame.
+func (d *Driver) MeasureContent(width float32, build func(nkc *nk.Context)) (w, h float32, err error) {
+	if !d.initialized {
+		return 0, 0, ErrNotInitialized
+	} else if width != width || width < 0 || width > maxMeasureSize {
+		// x != x means x is NaN
+		return 0, 0, fmt.Errorf("width(%g) is out of bounds", width)
+	}
+	if width == 0 {
+		width = maxMeasureSize
+	}
+	if d.measureCtx == nil {
+		if d.measureCtx, err = d.nkDriver.CreateContext(); err != nil {
+			return 0, 0, fmt.Errorf("creating Nuklear measurement context: %w", err)
+		}
+	}
+	nkc := d.measureCtx
+	defer nkc.Clear()
+	nkc.StyleSetFont(d.currentFont)
+	bounds := nk.Rect{W: width, H: maxMeasureSize}
+	if nkc.Begin(measureWindowName, &bounds, nk.WindowNoScrollbar|nk.WindowNoInput) {
+		build(nkc)
+	}
+	nkc.End()
+	var ext extent
+	nkc.ForEach(func(cmd nk.Command) bool {
+		ext.addCommand(cmd, width)
+		return true
+	})
+	if ext.empty() {
+		return 0, 0, nil
+	}
+	// the padding before the content is assumed to follow it too
+	return ext.maxX + ext.minX, ext.maxY + ext.minY, nil
+}
+
+// extent is the bounding box of draw commands.
+type extent struct {
+	minX, minY, maxX, maxY float32
+	nonEmpty               bool
+}
+
+func (e *extent) empty() bool {
+	return !e.nonEmpty
+}
+
+// add extends e to include the rectangle (x, y, w, h).
+func (e *extent) add(x, y, w, h float32) {
+	if !e.nonEmpty {
+		e.minX, e.minY, e.maxX, e.maxY = x, y, x+w, y+h
+		e.nonEmpty = true
+		return
+	}
+	if x < e.minX {
+		e.minX = x
+	}
+	if y < e.minY {
+		e.minY = y
+	}
+	if x+w > e.maxX {
+		e.maxX = x + w
+	}
+	if y+h > e.maxY {
+		e.maxY = y + h
+	}
+}
+
+// addPoints extends e to include points.
+func (e *extent) addPoints(points ...nk.Vec2i) {
+	for _, p := range points {
+		e.add(float32(p.X), float32(p.Y), 0, 0)
+	}
+}
+
+// addCommand extends e to include cmd, except for the background of the
+// measurement window, which is as wide as the window and as tall as
+// maxMeasureSize.
+func (e *extent) addCommand(cmd nk.Command, windowW float32) {
+	isBackground := func(x, y int16, w, h uint16) bool {
+		return x <= 0 && y <= 0 && float32(w) >= windowW && h >= maxMeasureSize/2
+	}
+	switch c := cmd.(type) {
+	case *nk.CommandLine:
+		e.addPoints(c.Begin, c.End)
+	case *nk.CommandCurve:
+		e.addPoints(c.Begin, c.End, c.Ctrl[0], c.Ctrl[1])
+	case *nk.CommandRect:
+		if !isBackground(c.X, c.Y, c.W, c.H) {
+			e.add(float32(c.X), float32(c.Y), float32(c.W), float32(c.H))
+		}
+	case *nk.CommandRectFilled:
+		if !isBackground(c.X, c.Y, c.W, c.H) {
+			e.add(float32(c.X), float32(c.Y), float32(c.W), float32(c.H))
+		}
+	case *nk.CommandRectMultiColor:
+		e.add(float32(c.X), float32(c.Y), float32(c.W), float32(c.H))
+	case *nk.CommandCircle:
+		e.add(float32(c.X), float32(c.Y), float32(c.W), float32(c.H))
+	case *nk.CommandCircleFilled:
+		e.add(float32(c.X), float32(c.Y), float32(c.W), float32(c.H))
+	case *nk.CommandArc:
+		e.add(float32(c.CX)-float32(c.R), float32(c.CY)-float32(c.R), 2*float32(c.R), 2*float32(c.R))
+	case *nk.CommandArcFilled:
+		e.add(float32(c.CX)-float32(c.R), float32(c.CY)-float32(c.R), 2*float32(c.R), 2*float32(c.R))
+	case *nk.CommandTriangle:
+		e.addPoints(c.A, c.B, c.C)
+	case *nk.CommandTriangleFilled:
+		e.addPoints(c.A, c.B, c.C)
+	case *nk.CommandPolygon:
+		e.addPoints(c.Points()...)
+	case *nk.CommandPolygonFilled:
+		e.addPoints(c.Points()...)
+	case *nk.CommandPolyline:
+		e.addPoints(c.Points()...)
+	case *nk.CommandImage:
+		e.add(float32(c.X), float32(c.Y), float32(c.W), float32(c.H))
+	case *nk.CommandText:
+		e.add(float32(c.X), float32(c.Y), float32(c.W), float32(c.H))
+	}
+}
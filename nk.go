@@ -1,6 +1,8 @@
 package nksdl
 
 import (
+	"errors"
+	"fmt"
 	"unsafe"
 
 	"github.com/kbolino/go-nk"
@@ -17,13 +19,56 @@ var (
 	}
 )
 
+// vertexFormatSizes are the sizes in bytes of the vertex attributes written
+// by Nuklear in the formats of vertexLayout: two floats for a position or
+// texture coordinate, and four bytes for a color.
+var vertexFormatSizes = map[nk.DrawVertexLayoutFormat]uintptr{
+	nk.FormatFloat:    2 * unsafe.Sizeof(float32(0)),
+	nk.FormatR8G8B8A8: 4,
+}
+
+// checkVertexLayout returns an error if the vertices that Nuklear writes with
+// vertexLayout, vertexSize, and vertexAlignment would not have the memory
+// layout of sdl.Vertex, which would make the renderer draw garbage.
+func checkVertexLayout() error {
+	fieldSizes := [...]uintptr{
+		unsafe.Sizeof(sdl.Vertex{}.Position),
+		unsafe.Sizeof(sdl.Vertex{}.Color),
+		unsafe.Sizeof(sdl.Vertex{}.TexCoord),
+	}
+	layoutErr := func(reason string) error {
+		return fmt.Errorf("vertex layout is incompatible with sdl.Vertex: %s "+
+			"(offsets: position=%d, color=%d, texcoord=%d; field sizes: %d, %d, %d; vertex size=%d, alignment=%d)",
+			reason, vertexLayout[0].Offset, vertexLayout[1].Offset, vertexLayout[2].Offset,
+			fieldSizes[0], fieldSizes[1], fieldSizes[2], vertexSize, vertexAlignment)
+	}
+	if vertexSize == 0 || vertexAlignment == 0 || vertexSize%vertexAlignment != 0 {
+		return layoutErr("vertex size is not a multiple of alignment")
+	}
+	var end uintptr
+	for i, elem := range vertexLayout {
+		if size := vertexFormatSizes[elem.Format]; size != fieldSizes[i] {
+			return layoutErr(fmt.Sprintf("attribute %d is written as %d bytes into a %d byte field", i, size, fieldSizes[i]))
+		} else if elem.Offset < end {
+			return layoutErr(fmt.Sprintf("attribute %d overlaps the previous attribute", i))
+		} else if elem.Offset+fieldSizes[i] > vertexSize {
+			return layoutErr(fmt.Sprintf("attribute %d extends past the end of the vertex", i))
+		}
+		end = elem.Offset + fieldSizes[i]
+	}
+	return nil
+}
+
 // NkDriver is implemented by any type capable of initializing Nuklear and its
 // core resources.
 type NkDriver interface {
 	CreateContext() (*nk.Context, error)
 	CreateFontAtlas() (*nk.FontAtlas, error)
 	CreateFont(atlas *nk.FontAtlas, scale float32) (*nk.Font, error)
+	BakeFontAtlas(atlas *nk.FontAtlas) (image []byte, width, height int32, err error)
+	ConvertOpts() ConvertOpts
 	CreateConvertConfig(
+		opts ConvertOpts,
 		vertexLayout []nk.DrawVertexLayoutElement,
 		vertexSize, vertexAlignment uint32,
 		null nk.DrawNullTexture,
@@ -51,25 +96,51 @@ func (d *DefaultNkDriver) CreateFontAtlas() (*nk.FontAtlas, error) {
 }
 
 func (d *DefaultNkDriver) CreateFont(atlas *nk.FontAtlas, scale float32) (*nk.Font, error) {
-	if d.Font.Path == "" {
-		return atlas.AddDefaultFont(d.Font.Size*scale, nil), nil
+	return addFont(atlas, d.Font, scale)
+}
+
+// addFont adds a font to atlas as given by opts, with its size scaled by
+// scale.
+func addFont(atlas *nk.FontAtlas, opts FontOpts, scale float32) (*nk.Font, error) {
+	if opts.Path == "" {
+		return atlas.AddDefaultFont(opts.Size*scale, nil), nil
 	} else {
-		return atlas.AddFromFile(d.Font.Path, d.Font.Size*scale, nil)
+		return atlas.AddFromFile(opts.Path, opts.Size*scale, nil)
+	}
+}
+
+func (d *DefaultNkDriver) BakeFontAtlas(atlas *nk.FontAtlas) ([]byte, int32, int32, error) {
+	image, width, height := atlas.Bake(nk.FontAtlasRGBA32)
+	if image == nil {
+		return nil, 0, 0, errors.New("font baking returned nil image")
+	}
+	if d.Font.MaxAtlasWidth > 0 && width > d.Font.MaxAtlasWidth ||
+		d.Font.MaxAtlasHeight > 0 && height > d.Font.MaxAtlasHeight {
+		return nil, 0, 0, fmt.Errorf("baked font atlas (%dx%d) exceeds maximum size (%dx%d)",
+			width, height, d.Font.MaxAtlasWidth, d.Font.MaxAtlasHeight)
 	}
+	return image, width, height, nil
+}
+
+// ConvertOpts returns d.Convert, the initial options for creating vertex
+// buffer conversion configurations.
+func (d *DefaultNkDriver) ConvertOpts() ConvertOpts {
+	return d.Convert
 }
 
 func (d *DefaultNkDriver) CreateConvertConfig(
+	opts ConvertOpts,
 	vertexLayout []nk.DrawVertexLayoutElement,
 	vertexSize, vertexAlignment uint32,
 	null nk.DrawNullTexture,
 ) *nk.ConvertConfig {
 	return nk.ConvertConfigBuilder{
-		GlobalAlpha:        d.Convert.GlobalAlpha,
-		LineAA:             d.Convert.LineAA,
-		ShapeAA:            d.Convert.ShapeAA,
-		CircleSegmentCount: d.Convert.CircleSegmentCount,
-		CurveSegmentCount:  d.Convert.CurveSegmentCount,
-		ArcSegmentCount:    d.Convert.ArcSegmentCount,
+		GlobalAlpha:        opts.GlobalAlpha,
+		LineAA:             opts.LineAA,
+		ShapeAA:            opts.ShapeAA,
+		CircleSegmentCount: opts.CircleSegmentCount,
+		CurveSegmentCount:  opts.CurveSegmentCount,
+		ArcSegmentCount:    opts.ArcSegmentCount,
 		VertexLayout:       vertexLayout,
 		VertexSize:         vertexSize,
 		VertexAlignment:    vertexAlignment,
@@ -78,6 +149,8 @@ func (d *DefaultNkDriver) CreateConvertConfig(
 }
 
 // ConvertOpts contains options used by DefaultNkContext.CreateConvertConfig.
+// Driver starts with the options returned by NkDriver.ConvertOpts, and some of
+// them can be changed at runtime, e.g. with Driver.SetAntiAliasing.
 type ConvertOpts struct {
 	GlobalAlpha        float32
 	LineAA, ShapeAA    nk.AntiAliasing
@@ -86,8 +159,16 @@ type ConvertOpts struct {
 	ArcSegmentCount    uint32
 }
 
-// FontOpts contains options used by DefaultNkContext.CreateFont.
+// FontOpts contains options used by DefaultNkContext.CreateFont and
+// DefaultNkContext.BakeFontAtlas.
 type FontOpts struct {
 	Path string
 	Size float32
+	// MaxAtlasWidth and MaxAtlasHeight, if positive, limit the dimensions of
+	// the baked font atlas. Nuklear picks the atlas dimensions itself, based
+	// on the number and size of the glyphs, so these are ceilings rather than
+	// targets: baking fails if the atlas does not fit. A larger atlas holds
+	// more (or larger) glyphs at the cost of more texture memory, and it can
+	// never exceed the maximum texture size of the renderer.
+	MaxAtlasWidth, MaxAtlasHeight int32
 }
@@ -5,6 +5,9 @@ package nksdl
 import (
 	"errors"
 	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
 
 	"github.com/kbolino/go-nk"
 	"github.com/veandco/go-sdl2/sdl"
@@ -23,26 +26,90 @@ type Driver struct {
 	window   *sdl.Window
 	renderer *sdl.Renderer
 	fontTex  *sdl.Texture
+	fontTexW int32
+	fontTexH int32
+	images   []*sdl.Texture // textures of images loaded by the Driver
+
+	textureResolver TextureResolver
 
 	context     *nk.Context
 	atlas       *nk.FontAtlas
 	font        *nk.Font
 	largeFont   *nk.Font
-	null        nk.DrawNullTexture
+	namedFonts  map[string]*namedFont
+	currentFont *nk.UserFont       // font currently set in the context style
+	largeFonts  bool               // whether large fonts are used in this frame
+	fontChanged time.Time          // when SetFontSize last changed a size, or zero
+	null        nk.DrawNullTexture // null texture in use
+	atlasNull   nk.DrawNullTexture // null texture of the font atlas
+	nullTex     *sdl.Texture       // null texture set by SetNullTexture
+	convertOpts ConvertOpts
 	convertConf *nk.ConvertConfig
 	commands    *nk.Buffer
 	elements    *nk.Buffer
 	vertices    *nk.Buffer
+	measureCtx  *nk.Context // private context of MeasureContent
+	layers      []layer     // contexts rendered above context
+
+	asyncFonts bool                // whether fonts are loaded in the background
+	fontLoad   chan fontLoadResult // result of loading fonts in the background
+	fontsReady func()
+	shared     *SharedFontAtlas // used instead of baking fonts, if not nil
+
+	now        func() time.Time // source of the current time
+	frameTime  time.Time        // time at which the current frame started
+	deltaTime  time.Duration    // time elapsed between the last two frames
+	frameCount uint64           // number of frames started
+	frameTimes frameTimeHistory // recent values of deltaTime
+
+	textInput   textInputState
+	cursors     cursorState
+	colorFilter colorFilterState
+	idle        idleState
+	onEscape    func()
+	idleWork    func(budget time.Duration)
+	onUnhandled func(event sdl.Event)
+	vertexFunc  VertexFunc
+	hotkeys     []hotkey
+	frameInput  FrameInput // input consumed by Nuklear in this frame
+
+	quitRequested bool // whether RequestQuit has been called
+	quitPending   bool // whether a quit awaits confirmation
+	confirmQuit   bool // whether quitting requires confirmation
+	closeBehavior CloseBehavior
+	closeVetoed   bool // whether the last close was intercepted in this poll
+
+	initialized bool // whether Init has succeeded
+	cleared     bool // whether the context was cleared since the last render
+	inputOpen   bool // whether input is between BeginInput and EndInput
+	noPresent   bool // whether FrameEnd leaves presenting to the application
+	inFrame     bool // whether a frame is between FrameStart and FrameEnd
+
+	refreshRate int     // refresh rate of the window's display, 0 if unknown
+	density     float32 // pixel density of the window, 0 if unknown
+
+	inputTicks   uint32        // SDL timestamp of the oldest unpresented input, 0 if none
+	inputLatency time.Duration // latency of the last presented input
 
-	renderScale   float32   // desired render scale
-	bgColor       sdl.Color // desired background color
-	clampClipRect bool      // whether to clamp clip rects
+	guiViewport sdl.Rect // sub-rectangle of the window for the GUI, if not empty
+	logicalW    int32    // logical width of the renderer, 0 if not in use
+	logicalH    int32    // logical height of the renderer, 0 if not in use
+
+	renderScale    float32   // desired render scale
+	minRenderScale float32   // lower bound on the render scale, 0 if none
+	autoScale      bool      // whether renderScale is computed automatically
+	dpiScale       bool      // whether the automatic scale uses the display DPI
+	bgColor        sdl.Color // desired background color
+	clampClipRect  bool      // whether to clamp clip rects
+	clipMargin     int32     // margin added to clip rects, in output pixels
+	maxIndices     int       // most indices per RenderGeometry call, 0 if unlimited
 }
 
 // NewDriver creates a new Driver from the given parameters. The sdlDriver and
 // nkDriver must not be nil, or else NewDriver will panic. The bindings map is
 // used to map keys to Nuklear actions. The eventListener is optional, but if
 // non-nil will be called for every SDL event after it is handled by Nuklear.
+// If eventListener is nil, QuitOnClose is used instead.
 func NewDriver(
 	sdlDriver SDLDriver,
 	nkDriver NkDriver,
@@ -59,23 +126,45 @@ func NewDriver(
 		nkDriver:      nkDriver,
 		eventListener: eventListener,
 		eventHandler:  NewEventHandler(bindings),
+		now:           time.Now,
 		renderScale:   1,
 		bgColor:       sdl.Color{R: 0, G: 0, B: 0, A: 255},
 	}
 }
 
+// Window returns the SDL window, which is nil before Init.
 func (d *Driver) Window() *sdl.Window {
 	return d.window
 }
 
+// Renderer returns the SDL renderer, which is nil before Init.
 func (d *Driver) Renderer() *sdl.Renderer {
 	return d.renderer
 }
 
+// Context returns the Nuklear context, which is nil before Init.
 func (d *Driver) Context() *nk.Context {
 	return d.context
 }
 
+// EventHandler returns the EventHandler used by d, which can be used to adjust
+// how input events are reported to Nuklear.
+func (d *Driver) EventHandler() *EventHandler {
+	return &d.eventHandler
+}
+
+// FontAtlasSize returns the dimensions of the baked font atlas, which are zero
+// before Init.
+func (d *Driver) FontAtlasSize() (width, height int32) {
+	return d.fontTexW, d.fontTexH
+}
+
+// DeltaTime returns the time elapsed between the starts of the current frame
+// and the previous frame. It is zero until the second frame has started.
+func (d *Driver) DeltaTime() time.Duration {
+	return d.deltaTime
+}
+
 func (d *Driver) BGColor() sdl.Color {
 	return d.bgColor
 }
@@ -89,33 +178,72 @@ func (d *Driver) RenderScale() float32 {
 	return d.renderScale
 }
 
+func (d *Driver) MinRenderScale() float32 {
+	return d.minRenderScale
+}
+
+// SetMinRenderScale sets a lower bound on the render scale, which applies to
+// both an explicit and an automatic render scale (see SetRenderScale). Raising
+// the floor enlarges the whole GUI, including its hit targets, e.g. for users
+// who need larger text or touch targets. A minRenderScale of 0 removes the
+// bound.
+func (d *Driver) SetMinRenderScale(minRenderScale float32) error {
+	// x != x means x is NaN
+	if minRenderScale != minRenderScale || minRenderScale < 0 || minRenderScale > 5 {
+		return fmt.Errorf("minRenderScale(%g) is out of bounds", minRenderScale)
+	}
+	d.minRenderScale = minRenderScale
+	return nil
+}
+
 // SetRenderScale sets the desired rendering scale. To compute the scale
-// automatically (e.g. on a high-DPI display), use a renderScale of 0.
+// automatically (e.g. on a high-DPI display), use a renderScale of 0; the
+// scale will then be recomputed when the window is resized and when displays
+// are connected or disconnected. If SetRenderScale(0) is called before Init,
+// the scale is computed by Init, so that it is correct from the first frame.
+// The render scale cannot be set while a logical size is set (see
+// SetLogicalSize).
 func (d *Driver) SetRenderScale(renderScale float32) error {
 	// x != x means x is NaN
 	if renderScale != renderScale || renderScale < 0 || renderScale > 5 {
 		return fmt.Errorf("renderScale(%g) is out of bounds", renderScale)
+	} else if d.logicalW != 0 {
+		return errors.New("render scale cannot be set while a logical size is set")
 	}
 	if renderScale == 0 {
-		if err := d.computeUIScale(); err != nil {
-			return fmt.Errorf("computing UI scale: %w", err)
+		if d.renderer != nil {
+			if err := d.computeUIScale(); err != nil {
+				return fmt.Errorf("computing UI scale: %w", err)
+			}
 		}
 	} else {
 		d.renderScale = renderScale
 	}
+	d.autoScale = renderScale == 0
 	return nil
 }
 
 // Init initializes the Driver, creating the SDL window and renderer as well
 // as the Nuklear context and fonts. Init should be called once in the lifetime
-// of a Driver, before any calls to FrameStart.
+// of a Driver, before any calls to FrameStart. Init fails if the version of SDL
+// in use is older than 2.0.18, and returns ErrAlreadyInitialized if Init has
+// already succeeded.
 func (d *Driver) Init() error {
+	if d.initialized {
+		return ErrAlreadyInitialized
+	}
 	var err error
 	defer func() {
 		if err != nil {
 			d.Destroy()
 		}
 	}()
+	if err = checkSDLVersion(); err != nil {
+		return err
+	}
+	if err = checkVertexLayout(); err != nil {
+		return err
+	}
 	if err = d.sdlDriver.InitSDL(); err != nil {
 		return fmt.Errorf("initializing SDL: %w", err)
 	}
@@ -138,64 +266,116 @@ func (d *Driver) Init() error {
 	if d.context, err = d.nkDriver.CreateContext(); err != nil {
 		return fmt.Errorf("creating Nuklear context: %w", err)
 	}
-	if d.atlas, err = d.nkDriver.CreateFontAtlas(); err != nil {
-		return fmt.Errorf("creating font atlast: %w", err)
-	}
-	if d.font, err = d.nkDriver.CreateFont(d.atlas, 1); err != nil {
-		return fmt.Errorf("creating font: %w", err)
-	}
-	if d.largeFont, err = d.nkDriver.CreateFont(d.atlas, 2); err != nil {
-		return fmt.Errorf("creating large font: %w", err)
+	d.convertOpts = d.nkDriver.ConvertOpts()
+	if d.shared != nil {
+		err = d.useSharedFonts()
+	} else if d.asyncFonts {
+		err = d.startFontLoad()
+	} else {
+		err = d.rebuildFonts()
 	}
-	if d.null, err = d.bakeFont(); err != nil {
-		return fmt.Errorf("baking font: %w", err)
+	if err != nil {
+		return err
 	}
-	largeFontHandle := d.largeFont.Handle()
-	largeFontHandle.SetHeight(largeFontHandle.Height() / 2)
-	d.convertConf = d.nkDriver.CreateConvertConfig(
-		vertexLayout,
-		uint32(vertexSize),
-		uint32(vertexAlignment),
-		d.null,
-	)
 	d.commands = nk.NewBuffer()
 	d.elements = nk.NewBuffer()
 	d.vertices = nk.NewBuffer()
+	if err := d.updateDisplay(); err != nil {
+		sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "updating display info: %s", err.Error())
+	}
+	d.updateDensity()
+	if d.autoScale {
+		if err = d.computeUIScale(); err != nil {
+			return fmt.Errorf("computing UI scale: %w", err)
+		}
+	}
+	if d.logicalW != 0 {
+		if err = d.renderer.SetLogicalSize(d.logicalW, d.logicalH); err != nil {
+			return fmt.Errorf("setting renderer logical size: %w", err)
+		}
+	}
+	if wakeType := sdl.RegisterEvents(1); wakeType != math.MaxUint32 {
+		d.eventHandler.wakeType = wakeType
+	} else {
+		sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "registering wake event: %s", lastSDLError().Error())
+	}
+	d.idle.focused = d.window.GetFlags()&sdl.WINDOW_INPUT_FOCUS != 0
+	d.markActive()
+	d.initialized = true
 	return nil
 }
 
 // FrameStart performs early frame actions, including polling for events,
 // mapping input events to actions, as well as scaling and clearing the
 // renderer. FrameStart should be called once at the beginning of every frame.
-func (d *Driver) FrameStart() error {
-	d.context.Clear()
-	d.context.InputBegin()
-	defer d.context.InputEnd()
-	alive := true
-	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-		eventType, usedByNuklear := d.eventHandler.HandleEvent(d.context, event)
-		if d.eventListener != nil {
-			if err := d.eventListener(event, eventType, usedByNuklear); err == ErrQuit {
-				alive = false
-			} else if err != nil {
-				return fmt.Errorf("passing event %#v to event listener: %w", event, err)
-			}
-		} else if eventType == EventTypeQuit {
-			alive = false
+//
+// FrameStart clears the Nuklear context, which discards the draw commands of
+// the previous frame along with any state that was not used in it. State is
+// keyed by name and survives only while it is used in every frame: the state
+// of a window (position, size, scroll offset, minimized, etc.) is discarded if
+// the window is not begun in a frame, and the state of a group or other widget
+// within a window (such as a group's scroll offset or an edit's cursor) is
+// discarded if that widget is not declared in a frame. To keep such state
+// across a structural change to the UI, keep declaring the affected windows
+// and groups, e.g. with nk.WindowHidden, rather than skipping them.
+func (d *Driver) FrameStart() (err error) {
+	if !d.initialized {
+		return ErrNotInitialized
+	} else if d.quitRequested {
+		return ErrQuit
+	}
+	event := d.waitIdle()
+	now := d.now()
+	if d.frameCount != 0 {
+		d.deltaTime = now.Sub(d.frameTime)
+		d.frameTimes.record(d.deltaTime)
+	}
+	d.frameTime = now
+	d.frameCount++
+	if err = d.ClearContext(); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			// nothing will be rendered, so let the next frame clear again
+			d.cleared = false
 		}
+	}()
+	if err := d.BeginInput(); err != nil {
+		return err
 	}
-	d.context.InputEnd()
-	if !alive {
+	d.frameInput = FrameInput{}
+	alive, err := d.pollEvents(event)
+	d.eventHandler.FlushMotion(d.context)
+	if d.eventHandler.RepeatKeys(d.context, now) {
+		d.markActive()
+	}
+	if err := d.EndInput(); err != nil {
+		return err
+	}
+	if err != nil {
+		return err
+	} else if !alive {
 		return ErrQuit
 	}
-	if d.renderScale > 1.5 {
-		d.context.StyleSetFont(d.largeFont.Handle())
-	} else {
-		d.context.StyleSetFont(d.font.Handle())
+	scale := d.renderScale
+	if scale < d.minRenderScale {
+		scale = d.minRenderScale
 	}
-	if err := d.renderer.SetScale(d.renderScale, d.renderScale); err != nil {
-		return fmt.Errorf("setting renderer scale to %g: %w", d.renderScale, err)
+	if d.logicalW != 0 {
+		// SDL scales the renderer itself to fit the logical size
+		scale, _ = d.renderer.GetScale()
+	} else if err := d.renderer.SetScale(scale, scale); err != nil {
+		return fmt.Errorf("setting renderer scale to %g: %w", scale, err)
 	}
+	if err := d.finishFontLoad(); err != nil {
+		return err
+	}
+	if err := d.maybeRebuildFonts(); err != nil {
+		return err
+	}
+	d.largeFonts = scale > 1.5
+	d.setDefaultFont()
 	oldR, oldG, oldB, oldA, err := d.renderer.GetDrawColor()
 	if err != nil {
 		return fmt.Errorf("getting renderer draw color: %w", err)
@@ -207,41 +387,385 @@ func (d *Driver) FrameStart() error {
 		return fmt.Errorf("clearing renderer: %w", err)
 	}
 	if err := d.renderer.SetDrawColor(oldR, oldG, oldB, oldA); err != nil {
-		err = fmt.Errorf("restoring renderer draw color: %w", err)
+		return fmt.Errorf("restoring renderer draw color: %w", err)
 	}
+	d.inFrame = true
+	return nil
+}
+
+// PumpFrame runs one whole frame, calling build between FrameStart and
+// FrameEnd to declare the GUI, and returns the first error of either, e.g.
+// ErrQuit once a quit has been requested. This makes it easy to run a nested
+// modal loop, e.g. for a file dialog drawn with Nuklear, which calls PumpFrame
+// until the dialog is dismissed or PumpFrame returns an error:
+//
+//	for !done {
+//		if err := driver.PumpFrame(buildDialog); err != nil {
+//			return err
+//		}
+//	}
+//
+// Nuklear cannot nest frames, so PumpFrame must be called between frames, e.g.
+// after FrameEnd in response to a flag set while declaring the GUI, and not
+// from within a frame, including from build in another call to PumpFrame. If it
+// is, it returns an error without touching the frame in progress or its input.
+func (d *Driver) PumpFrame(build func(nkc *nk.Context)) error {
+	if d.inFrame {
+		return errors.New("PumpFrame cannot be called during a frame")
+	}
+	if err := d.FrameStart(); err != nil {
+		return err
+	}
+	build(d.context)
+	return d.FrameEnd()
+}
+
+// OnEscape sets a callback to be invoked whenever the Escape key is pressed,
+// e.g. to dismiss a popup or menu. Nuklear has no key action of its own for
+// Escape, so there is no default binding for it. The callback is invoked from
+// FrameStart, after Nuklear has handled the key event and before the event is
+// passed to the EventListener. A nil callback disables this behavior.
+func (d *Driver) OnEscape(callback func()) {
+	d.onEscape = callback
+}
+
+// Wake wakes the Driver if it is waiting for events in FrameStart, e.g. while
+// idle or unfocused (see SetIdleOpts and SetUnfocusedFPS), so that the next
+// frame renders promptly, e.g. to show data that a background goroutine has
+// just loaded. It does so by pushing an SDL event of EventTypeWake, which is
+// passed to the EventListener but otherwise ignored. Unlike nearly all other
+// methods of Driver, Wake is safe to call from any goroutine, since
+// SDL_PushEvent is thread-safe, as long as Init has returned before the call.
+// Calls made while a wake event is already in the queue are coalesced into it.
+func (d *Driver) Wake() error {
+	h := &d.eventHandler
+	if h.wakeType == 0 {
+		return errors.New("wake event is not registered")
+	} else if !atomic.CompareAndSwapInt32(&h.wakePending, 0, 1) {
+		return nil
+	}
+	if _, err := sdl.PushEvent(&sdl.UserEvent{Type: h.wakeType}); err != nil {
+		atomic.StoreInt32(&h.wakePending, 0)
+		return fmt.Errorf("pushing wake event: %w", err)
+	}
+	return nil
+}
+
+// SetUnhandledEventHandler sets a callback to be invoked for every event that
+// neither the EventHandler nor the Driver handles, i.e. every event of
+// EventTypeUnhandled other than window events, such as audio device, sensor,
+// joystick, and user events. This suits events that Nuklear never cares
+// about better than the EventListener, which receives every event. Such
+// events are still passed to the EventListener afterwards. The callback is
+// invoked from FrameStart, while input for Nuklear is being gathered, so it
+// must not declare any GUI. A nil callback disables this behavior.
+func (d *Driver) SetUnhandledEventHandler(callback func(event sdl.Event)) {
+	d.onUnhandled = callback
+}
+
+// RequestQuit causes the next call to FrameStart to return ErrQuit. Together
+// with an EventListener that does not return ErrQuit for EventTypeQuit, this
+// decouples closing the window from quitting the application, e.g. so that the
+// application can ask the user for confirmation first. RequestQuit also
+// confirms a pending quit (see SetConfirmQuit).
+func (d *Driver) RequestQuit() {
+	d.quitRequested = true
+	d.quitPending = false
+}
+
+func (d *Driver) ConfirmQuit() bool {
+	return d.confirmQuit
+}
+
+// SetConfirmQuit sets whether quitting requires confirmation. If it does, then
+// an EventListener returning ErrQuit (as QuitOnClose does when the user closes
+// the application) no longer causes FrameStart to return ErrQuit; instead, it
+// makes a quit pending, as reported by QuitPending. The application can then
+// e.g. show a "Save changes?" dialog, and either confirm the quit by calling
+// RequestQuit or cancel it by calling CancelQuit.
+func (d *Driver) SetConfirmQuit(confirmQuit bool) {
+	d.confirmQuit = confirmQuit
+}
+
+// QuitPending returns true if a quit awaits confirmation (see SetConfirmQuit).
+func (d *Driver) QuitPending() bool {
+	return d.quitPending
+}
+
+// CancelQuit cancels a pending quit (see SetConfirmQuit).
+func (d *Driver) CancelQuit() {
+	d.quitPending = false
+}
+
+// LastFrameInput returns a summary of the input events consumed by Nuklear in
+// the current frame, as gathered by the last call to FrameStart. This helps to
+// debug the routing of input between the GUI and the rest of the application.
+func (d *Driver) LastFrameInput() FrameInput {
+	return d.frameInput
+}
+
+// FlushInput discards the input gathered by FrameStart, so that widgets built
+// after the call see no clicks, key presses, scrolling, or text input from the
+// current frame. Held buttons and keys stay held and the cursor position is
+// kept. This prevents click-through when the UI changes drastically within a
+// frame, e.g. when the click that opens a modal would otherwise also activate
+// a widget behind it. FlushInput should only be called between FrameStart and
+// FrameEnd.
+func (d *Driver) FlushInput() {
+	d.context.InputBegin()
+	d.context.InputEnd()
+	for _, l := range d.layers {
+		l.context.InputBegin()
+		l.context.InputEnd()
+	}
+}
+
+// ClearContext clears the Nuklear context, discarding the draw commands of the
+// previous frame (see FrameStart). Together with BeginInput, EndInput, and
+// RenderFrame, ClearContext allows an application to compose frames itself,
+// e.g. to fit an existing engine loop, instead of calling FrameStart and
+// FrameEnd, which are built on top of them. Such a frame consists of:
+//
+//  1. ClearContext
+//  2. BeginInput, then reporting events to EventHandler().HandleEvent, then
+//     EndInput
+//  3. GUI operations
+//  4. RenderFrame, then presenting the renderer
+//
+// ClearContext fails if input has begun but not ended, or if the context has
+// already been cleared since the last call to RenderFrame.
+func (d *Driver) ClearContext() error {
+	if !d.initialized {
+		return ErrNotInitialized
+	} else if d.inputOpen {
+		return errors.New("cannot clear context while input is open")
+	} else if d.cleared {
+		return errors.New("context was already cleared for this frame")
+	}
+	d.context.Clear()
+	for _, l := range d.layers {
+		l.context.Clear()
+	}
+	d.cleared = true
+	return nil
+}
+
+// BeginInput begins gathering input for Nuklear (see ClearContext). It fails
+// if input has already begun.
+func (d *Driver) BeginInput() error {
+	if !d.initialized {
+		return ErrNotInitialized
+	} else if d.inputOpen {
+		return errors.New("input has already begun")
+	}
+	d.context.InputBegin()
+	for _, l := range d.layers {
+		l.context.InputBegin()
+	}
+	d.inputOpen = true
+	return nil
+}
+
+// EndInput ends gathering input for Nuklear (see ClearContext). It fails if
+// input has not begun.
+func (d *Driver) EndInput() error {
+	if !d.initialized {
+		return ErrNotInitialized
+	} else if !d.inputOpen {
+		return errors.New("input has not begun")
+	}
+	d.context.InputEnd()
+	for _, l := range d.layers {
+		l.context.InputEnd()
+	}
+	d.inputOpen = false
 	return nil
 }
 
 // FrameEnd performs late frame actions, including converting UI draw commands
 // to vertex buffer draw commands, passing the vertex buffers to the renderer,
-// and presenting the renderer. FrameEnd should be called once at the end of
-// every frame.
-func (d *Driver) FrameEnd() (err error) {
-	d.commands.Clear()
-	d.elements.Clear()
-	d.vertices.Clear()
-	if err = d.context.Convert(d.commands, d.vertices, d.elements, d.convertConf); err != nil {
-		return fmt.Errorf("converting render commands: %w", err)
+// and presenting the renderer, unless automatic presenting is disabled (see
+// SetAutoPresent). FrameEnd should be called once at the end of every frame.
+func (d *Driver) FrameEnd() error {
+	if !d.initialized {
+		return ErrNotInitialized
 	}
-	oldClipRect := d.renderer.GetClipRect()
-	viewport := d.renderer.GetViewport()
-	indices := reinterpretSlice[int32](d.elements.Memory(), 4)
-	vertices := reinterpretSlice[sdl.Vertex](d.vertices.Memory(), int(vertexSize))
-	d.context.DrawForEach(d.commands, func(cmd *nk.DrawCommand) bool {
-		if cmd.ElemCount == 0 {
-			return true
+	if err := d.finishFrame(); err != nil {
+		return err
+	}
+	d.runIdleWork()
+	if !d.noPresent {
+		d.present()
+	}
+	return nil
+}
+
+func (d *Driver) AutoPresent() bool {
+	return !d.noPresent
+}
+
+// SetAutoPresent sets whether FrameEnd presents the renderer, which it does by
+// default. An application that composites the GUI into a larger frame, e.g.
+// by drawing overlays on top of it, or that coordinates presenting with vsync
+// itself, can disable this; it must then call Present, or present the
+// renderer itself, after every FrameEnd, or nothing will be shown.
+func (d *Driver) SetAutoPresent(autoPresent bool) {
+	d.noPresent = !autoPresent
+}
+
+// Present presents the renderer, showing everything rendered since the last
+// time it was presented. Present is only needed if automatic presenting is
+// disabled (see SetAutoPresent).
+func (d *Driver) Present() error {
+	if !d.initialized {
+		return ErrNotInitialized
+	}
+	d.present()
+	return nil
+}
+
+// present presents the renderer and records the latency of the input shown by
+// the presented frame, if any.
+func (d *Driver) present() {
+	d.renderer.Present()
+	if d.inputTicks != 0 {
+		d.inputLatency = time.Duration(sdl.GetTicks()-d.inputTicks) * time.Millisecond
+		d.inputTicks = 0
+	}
+}
+
+// LastInputLatency returns the input latency of the last frame that showed the
+// result of input, i.e. the time from the oldest input event handled for that
+// frame until the frame was presented. It is zero until such a frame has been
+// presented. Comparing it to DeltaTime shows whether latency comes from the
+// event queue, e.g. because input waited for a slow frame, or from rendering.
+//
+// SDL timestamps events in whole milliseconds, so the latency is only accurate
+// to about a millisecond. It ends when the renderer's Present returns, which
+// with vsync is when the frame is queued for display, so it does not include
+// the latency of the compositor or the display itself, nor the time the event
+// spent in the operating system before reaching SDL. As event timestamps come
+// from SDL's tick counter, so does the end of the latency, so the latency is
+// real time even when the Driver's clock is not, e.g. under TestDriver.
+func (d *Driver) LastInputLatency() time.Duration {
+	return d.inputLatency
+}
+
+// finishFrame performs the late frame actions of FrameEnd which come before
+// presenting the renderer.
+func (d *Driver) finishFrame() error {
+	d.inFrame = false
+	d.updateTextInput()
+	if err := d.RenderFrame(d.commands, d.vertices, d.elements); err != nil {
+		return err
+	}
+	if err := d.drawRemoteCursors(); err != nil {
+		return err
+	}
+	if err := d.drawSoftwareCursor(); err != nil {
+		return err
+	}
+	if err := d.applyColorFilter(); err != nil {
+		return fmt.Errorf("applying color filter: %w", err)
+	}
+	return nil
+}
+
+// RenderFrame converts the UI draw commands of the current frame to vertex
+// buffer draw commands and passes them to the renderer, without presenting it.
+// FrameEnd calls RenderFrame with buffers owned by d, but an application which
+// manages its allocations centrally can call RenderFrame instead, with its own
+// buffers. The buffers remain owned by the caller: RenderFrame clears them
+// before use, and does not retain them after it returns, so they can be reused
+// in every frame, and must be freed by the caller when no longer needed. Note
+// that FrameEnd performs other late frame actions besides RenderFrame. The
+// layers of the Driver (see AddLayer), if any, are rendered after its context,
+// in the order they were added, using the same buffers.
+func (d *Driver) RenderFrame(commands, vertexBuf, elementBuf *nk.Buffer) error {
+	if !d.initialized {
+		return ErrNotInitialized
+	}
+	d.cleared = false
+	if err := d.renderContext(d.context, commands, vertexBuf, elementBuf); err != nil {
+		return err
+	}
+	for i, l := range d.layers {
+		if err := d.renderContext(l.context, commands, vertexBuf, elementBuf); err != nil {
+			return fmt.Errorf("rendering layer %d: %w", i, err)
 		}
+	}
+	return nil
+}
 
+// renderContext converts the UI draw commands of nkc and passes them to the
+// renderer.
+func (d *Driver) renderContext(nkc *nk.Context, commands, vertexBuf, elementBuf *nk.Buffer) (err error) {
+	if err = d.convert(nkc, commands, vertexBuf, elementBuf); err != nil {
+		return err
+	}
+	oldClipRect := d.renderer.GetClipRect()
+	oldViewport := d.renderer.GetViewport()
+	// restore the clip rect and viewport however rendering ends, so that the
+	// transient state of the GUI never leaks into the application's rendering
+	defer func() {
+		restoreClipRect := &oldClipRect
+		if restoreClipRect.Empty() {
+			restoreClipRect = nil
+		}
+		if err2 := d.renderer.SetClipRect(restoreClipRect); err2 != nil && err == nil {
+			err = fmt.Errorf("restoring clip rect: %w", err2)
+		}
+	}()
+	viewport := oldViewport
+	if !d.guiViewport.Empty() {
+		defer func() {
+			if err2 := d.renderer.SetViewport(&oldViewport); err2 != nil && err == nil {
+				err = fmt.Errorf("restoring viewport: %w", err2)
+			}
+		}()
+		viewport = d.guiViewport
+		if err = d.renderer.SetViewport(&viewport); err != nil {
+			return fmt.Errorf("setting renderer viewport: %w", err)
+		}
+	}
+	marginX, marginY := d.clipMarginScaled()
+	orient := &d.eventHandler.orient
+	orient.w, orient.h = viewport.W, viewport.H
+	if !orient.identity() || d.vertexFunc != nil {
+		vertices := reinterpretSlice[sdl.Vertex](vertexBuf.Memory(), int(vertexSize))
+		if !orient.identity() {
+			d.orientVertices(vertices)
+		}
+		if d.vertexFunc != nil {
+			d.vertexFunc(vertices)
+		}
+	}
+	err = d.drawForEach(nkc, commands, vertexBuf, elementBuf, func(cmd *nk.DrawCommand, vertices []sdl.Vertex, indices []int32) error {
 		clipRect := sdl.Rect{
 			X: int32(cmd.ClipRect.X) - 1,
 			Y: int32(cmd.ClipRect.Y),
 			W: int32(cmd.ClipRect.W) + 2,
 			H: int32(cmd.ClipRect.H),
 		}
+		if !orient.identity() {
+			clipRect = orient.rectToScreen(clipRect)
+		}
+		if marginX != 0 || marginY != 0 {
+			clipRect.X -= marginX
+			clipRect.Y -= marginY
+			clipRect.W += 2 * marginX
+			clipRect.H += 2 * marginY
+			if clipRect.W < 0 {
+				clipRect.W = 0
+			}
+			if clipRect.H < 0 {
+				clipRect.H = 0
+			}
+		}
 
 		if d.clampClipRect {
 			if clipRect.X < 0 {
-				clipRect.W += clipRect.H
+				clipRect.W += clipRect.X
 				clipRect.X = 0
 			}
 			if clipRect.Y < 0 {
@@ -256,59 +780,138 @@ func (d *Driver) FrameEnd() (err error) {
 			}
 		}
 
-		if err = d.renderer.SetClipRect(&clipRect); err != nil {
-			err = fmt.Errorf("setting renderer clip rectangle: %w", err)
-			return false
+		if err := d.renderer.SetClipRect(&clipRect); err != nil {
+			return fmt.Errorf("setting renderer clip rectangle to %v: %w", clipRect, err)
 		}
-		texture := handleToTexture(cmd.Texture)
-		if err = d.renderer.RenderGeometry(texture, vertices, indices[:cmd.ElemCount]); err != nil {
-			err = fmt.Errorf("rendering raw geometry: %w", err)
-			return false
+		texture, err := d.resolveTexture(cmd.Texture)
+		if err != nil {
+			return err
 		}
-		indices = indices[cmd.ElemCount:]
-		return true
+		for len(indices) != 0 {
+			n := len(indices)
+			if d.maxIndices != 0 && n > d.maxIndices {
+				n = d.maxIndices
+			}
+			if err := d.renderer.RenderGeometry(texture, vertices, indices[:n]); err != nil {
+				return fmt.Errorf("rendering geometry (%d indices, texture handle %#x): %w", n, cmd.Texture, err)
+			}
+			indices = indices[n:]
+		}
+		return nil
 	})
 	if err != nil {
 		return fmt.Errorf("error in context.DrawForEach: %w", err)
 	}
-	restoreClipRect := &oldClipRect
-	if restoreClipRect.Empty() {
-		restoreClipRect = nil
+	return nil
+}
+
+// ForEachDrawCommand converts the UI draw commands of the current frame to
+// vertex buffer draw commands, as RenderFrame does, and calls fn for each one
+// with the vertices of the whole frame and the indices of the command, without
+// rendering anything. This allows an application to inspect the geometry, e.g.
+// to feed it to a custom compositor. The slices are only valid during the call
+// to fn, and must not be modified. If fn returns an error, iteration stops and
+// the error is returned. The conversion uses buffers owned by d, which FrameEnd
+// converts into again, so ForEachDrawCommand adds the cost of a conversion.
+func (d *Driver) ForEachDrawCommand(fn func(cmd *nk.DrawCommand, vertices []sdl.Vertex, indices []int32) error) error {
+	if !d.initialized {
+		return ErrNotInitialized
 	}
-	if err = d.renderer.SetClipRect(restoreClipRect); err != nil {
-		return fmt.Errorf("restoring clip rect: %w", err)
+	if err := d.convert(d.context, d.commands, d.vertices, d.elements); err != nil {
+		return err
+	}
+	return d.drawForEach(d.context, d.commands, d.vertices, d.elements, fn)
+}
+
+// convert clears the given buffers and converts the UI draw commands of the
+// current frame of nkc into them.
+func (d *Driver) convert(nkc *nk.Context, commands, vertexBuf, elementBuf *nk.Buffer) error {
+	commands.Clear()
+	elementBuf.Clear()
+	vertexBuf.Clear()
+	if err := nkc.Convert(commands, vertexBuf, elementBuf, d.convertConf); err != nil {
+		return fmt.Errorf("converting render commands: %w", err)
 	}
-	d.renderer.Present()
 	return nil
 }
 
+// drawForEach calls fn for each non-empty draw command in commands, which must
+// have been converted from nkc into vertexBuf and elementBuf, with the vertices
+// of the whole frame and the indices of the command.
+func (d *Driver) drawForEach(
+	nkc *nk.Context,
+	commands, vertexBuf, elementBuf *nk.Buffer,
+	fn func(cmd *nk.DrawCommand, vertices []sdl.Vertex, indices []int32) error,
+) (err error) {
+	indices := reinterpretSlice[int32](elementBuf.Memory(), 4)
+	vertices := reinterpretSlice[sdl.Vertex](vertexBuf.Memory(), int(vertexSize))
+	nkc.DrawForEach(commands, func(cmd *nk.DrawCommand) bool {
+		if cmd.ElemCount == 0 {
+			return true
+		}
+		if err = fn(cmd, vertices, indices[:cmd.ElemCount]); err != nil {
+			return false
+		}
+		indices = indices[cmd.ElemCount:]
+		return true
+	})
+	return err
+}
+
 // Destroy fress resources used by the Driver. Destroy should be called once
-// in the lifetime of a Driver, after the last call to FrameEnd.
+// in the lifetime of a Driver, after the last call to FrameEnd. A Driver with
+// a shared font atlas (see SetSharedFontAtlas) does not own its window and
+// renderer, which other Drivers may still be using, so Destroy leaves them,
+// and SDL itself, to the application, which must destroy them and call
+// sdl.Quit once it has destroyed the last Driver and released the atlas.
 func (d *Driver) Destroy() (err error) {
-	defer sdl.Quit()
+	owned := d.shared == nil
+	if owned {
+		defer sdl.Quit()
+	}
+	defer freeCursors(d.cursors.system[:])
+	defer freeCursors(d.cursors.custom[:])
 	defer func() {
-		if d.window != nil {
+		if owned && d.window != nil {
 			if err2 := d.window.Destroy(); err2 != nil && err == nil {
 				err = err2
 			}
 		}
 	}()
 	defer func() {
-		if d.renderer != nil {
+		if owned && d.renderer != nil {
 			if err2 := d.renderer.Destroy(); err2 != nil && err == nil {
 				err = err2
 			}
 		}
 	}()
 	defer func() {
-		if d.fontTex != nil {
+		if err2 := d.destroyColorFilter(); err2 != nil && err == nil {
+			err = err2
+		}
+	}()
+	defer func() {
+		if err2 := d.destroyImages(); err2 != nil && err == nil {
+			err = err2
+		}
+	}()
+	defer func() {
+		if d.shared != nil && d.fontTex == d.shared.tex {
+			// the shared atlas is in use, so give up the reference to it
+			if err2 := d.shared.release(); err2 != nil && err == nil {
+				err = err2
+			}
+		} else if d.fontTex != nil {
 			if err2 := d.fontTex.Destroy(); err2 != nil && err == nil {
 				err = err2
 			}
 		}
 	}()
+	defer d.cancelFontLoad()
 	// all of the following calls are nil-safe
 	defer d.context.Free()
+	defer d.freeLayers()
+	defer d.measureCtx.Free()
 	defer d.atlas.Free()
 	defer d.convertConf.Free()
 	defer d.commands.Free()
@@ -317,25 +920,102 @@ func (d *Driver) Destroy() (err error) {
 	return nil
 }
 
-func (d *Driver) bakeFont() (nk.DrawNullTexture, error) {
-	image, width, height := d.atlas.Bake(nk.FontAtlasRGBA32)
-	if image == nil {
-		return nk.DrawNullTexture{}, errors.New("font baking returned nil image")
+// setClock replaces the source of the current time used by d, which is
+// time.Now by default. All time-dependent behavior of Driver must read the
+// time through d.now, so that it can be made deterministic, except for what
+// is measured against SDL event timestamps, which count SDL ticks rather than
+// wall time. LastInputLatency is the only such measurement, and it reads the
+// ticks from SDL.
+func (d *Driver) setClock(now func() time.Time) {
+	d.now = now
+}
+
+// pollEvents handles the given event, if not nil, and then all pending events.
+func (d *Driver) pollEvents(event sdl.Event) (alive bool, err error) {
+	alive = true
+	listener := d.eventListener
+	if listener == nil {
+		listener = QuitOnClose
 	}
-	var err error
-	d.fontTex, err = d.renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STATIC, width, height)
-	if err != nil {
-		return nk.DrawNullTexture{}, fmt.Errorf("creating font texture: %w", err)
+	if event == nil {
+		event = sdl.PollEvent()
 	}
-	if err = d.fontTex.Update(nil, image, int(4*width)); err != nil {
-		return nk.DrawNullTexture{}, fmt.Errorf("uploading font atlas to texture: %w", err)
+	notify := func(event sdl.Event, eventType EventType, usedByNuklear bool) error {
+		if err := listener(event, eventType, usedByNuklear); err == ErrQuit {
+			if d.confirmQuit {
+				d.quitPending = true
+			} else {
+				alive = false
+			}
+		} else if err != nil {
+			return fmt.Errorf("passing event %#v to event listener: %w", event, err)
+		}
+		return nil
+	}
+	for ; event != nil; event = sdl.PollEvent() {
+		eventType, usedByNuklear := d.eventHandler.HandleEvent(d.context, event)
+		d.frameInput.count(eventType, usedByNuklear)
+		densityChanged := false
+		switch e := event.(type) {
+		case *sdl.MouseMotionEvent:
+			d.cursors.x, d.cursors.y, d.cursors.inside = e.X, e.Y, true
+		case *sdl.WindowEvent:
+			densityChanged = d.handleWindowEvent(e)
+		case *sdl.DisplayEvent:
+			d.handleDisplayEvent(e)
+		}
+		if eventType.IsInput() {
+			d.markActive()
+			if d.inputTicks == 0 {
+				d.inputTicks = event.GetTimestamp()
+			}
+		}
+		if e, ok := event.(*sdl.KeyboardEvent); ok {
+			if d.onEscape != nil && e.Keysym.Sym == sdl.K_ESCAPE && e.State == sdl.PRESSED && e.Repeat == 0 {
+				d.onEscape()
+			}
+			d.handleHotkey(e, usedByNuklear)
+		}
+		if d.onUnhandled != nil && eventType == EventTypeUnhandled {
+			if _, ok := event.(*sdl.WindowEvent); !ok {
+				d.onUnhandled(event)
+			}
+		}
+		if d.interceptClose(eventType) {
+			continue
+		}
+		if err := notify(event, eventType, usedByNuklear); err != nil {
+			return false, err
+		}
+		if densityChanged {
+			if err := notify(event, EventTypeWindowDensityChanged, false); err != nil {
+				return false, err
+			}
+		}
 	}
-	if err = d.fontTex.SetBlendMode(sdl.BLENDMODE_BLEND); err != nil {
-		return nk.DrawNullTexture{}, fmt.Errorf("setting texture blend mode: %w", err)
+	d.closeVetoed = false
+	return alive, nil
+}
+
+// minSDLMajor, minSDLMinor, and minSDLPatch give the minimum version of SDL,
+// 2.0.18, which introduced SDL_RenderGeometry.
+const (
+	minSDLMajor = 2
+	minSDLMinor = 0
+	minSDLPatch = 18
+)
+
+// checkSDLVersion returns an error if the version of SDL linked at runtime is
+// older than the minimum version.
+func checkSDLVersion() error {
+	var ver sdl.Version
+	sdl.GetVersion(&ver)
+	if sdl.VERSIONNUM(int(ver.Major), int(ver.Minor), int(ver.Patch)) <
+		sdl.VERSIONNUM(minSDLMajor, minSDLMinor, minSDLPatch) {
+		return fmt.Errorf("SDL %d.%d.%d or later is required for SDL_RenderGeometry, but SDL %d.%d.%d is in use",
+			minSDLMajor, minSDLMinor, minSDLPatch, ver.Major, ver.Minor, ver.Patch)
 	}
-	null := d.atlas.End(textureToHandle(d.fontTex))
-	d.atlas.Cleanup()
-	return null, nil
+	return nil
 }
 
 func (d *Driver) computeUIScale() error {
@@ -352,6 +1032,11 @@ func (d *Driver) computeUIScale() error {
 			renderScaleX, renderScaleY)
 	}
 	d.renderScale = renderScaleY
+	if d.dpiScale && renderScaleY == 1 {
+		if scale, ok := d.displayDPIScale(); ok {
+			d.renderScale = scale
+		}
+	}
 	return nil
 }
 
@@ -362,10 +1047,33 @@ func (errQuit) Error() string {
 }
 
 // ErrQuit is an error sentinel value used to indicate that the application
-// should quit.
+// should quit. An EventListener returns ErrQuit to request a quit, which is
+// subject to confirmation if Driver.SetConfirmQuit is enabled, and FrameStart
+// returns ErrQuit once the quit has been requested and confirmed.
 var ErrQuit = errQuit{}
 
+// ErrNotInitialized is returned by methods of Driver which are called before
+// Init has succeeded.
+var ErrNotInitialized = errors.New("driver is not initialized")
+
+// ErrAlreadyInitialized is returned by Driver.Init if Init has already
+// succeeded.
+var ErrAlreadyInitialized = errors.New("driver is already initialized")
+
 // EventListener is the function signature for the optional event listener,
 // which is called after Nuklear handles an event. See the EventHandler type
-// for a description of the other parameters.
+// for a description of the other parameters. The event is passed as SDL
+// delivered it; EventHandler.EventPosition translates the position of a mouse
+// event to the coordinates used by Nuklear.
 type EventListener func(event sdl.Event, eventType EventType, usedByNuklear bool) error
+
+// QuitOnClose is an EventListener which returns ErrQuit for EventTypeQuit, i.e.
+// when the user closes the application. This is the behavior of Driver when no
+// EventListener is given. Other listeners can call QuitOnClose to keep this
+// behavior, or instead call Driver.RequestQuit at a later time.
+func QuitOnClose(event sdl.Event, eventType EventType, usedByNuklear bool) error {
+	if eventType == EventTypeQuit {
+		return ErrQuit
+	}
+	return nil
+}
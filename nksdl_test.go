@@ -0,0 +1,217 @@
+package nksdl
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// buildButton declares a window with a single button.
+func buildButton(nkc *nk.Context) {
+	if nkc.Begin("test", &nk.Rect{W: 200, H: 100}, nk.WindowNoScrollbar) {
+		nkc.LayoutRowStatic(30, 80, 1)
+		nkc.ButtonText("OK")
+	}
+	nkc.End()
+}
+
+func TestRenderFrameRestoresRendererState(t *testing.T) {
+	td := newTestDriver(t)
+	clipRect := sdl.Rect{X: 5, Y: 6, W: 70, H: 80}
+	viewport := sdl.Rect{X: 1, Y: 2, W: 150, H: 90}
+	if err := td.Renderer().SetViewport(&viewport); err != nil {
+		t.Fatal(err)
+	}
+	if err := td.Renderer().SetClipRect(&clipRect); err != nil {
+		t.Fatal(err)
+	}
+	if err := td.SetGUIViewport(sdl.Rect{X: 10, Y: 10, W: 100, H: 50}); err != nil {
+		t.Fatal(err)
+	}
+	if err := td.Frame(buildButton); err != nil {
+		t.Fatal(err)
+	}
+	if got := td.Renderer().GetClipRect(); got != clipRect {
+		t.Errorf("clip rect after frame is %v, want %v", got, clipRect)
+	}
+	if got := td.Renderer().GetViewport(); got != viewport {
+		t.Errorf("viewport after frame is %v, want %v", got, viewport)
+	}
+}
+
+func TestRenderFrameRestoresNoClipRect(t *testing.T) {
+	td := newTestDriver(t)
+	if err := td.Frame(buildButton); err != nil {
+		t.Fatal(err)
+	}
+	if got := td.Renderer().GetClipRect(); !got.Empty() {
+		t.Errorf("clip rect after frame is %v, want none", got)
+	}
+}
+
+// foreignTexture returns a texture created by a renderer other than that of
+// td, which td cannot render with. It is destroyed when t ends.
+func foreignTexture(t *testing.T) *sdl.Texture {
+	t.Helper()
+	window, err := sdl.CreateWindow("foreign", 0, 0, 1, 1, sdl.WINDOW_HIDDEN)
+	if err != nil {
+		t.Fatalf("creating window: %v", err)
+	}
+	t.Cleanup(func() { window.Destroy() })
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_SOFTWARE)
+	if err != nil {
+		t.Fatalf("creating renderer: %v", err)
+	}
+	t.Cleanup(func() { renderer.Destroy() })
+	texture, err := renderer.CreateTexture(uint32(sdl.PIXELFORMAT_RGBA32), sdl.TEXTUREACCESS_STATIC, 1, 1)
+	if err != nil {
+		t.Fatalf("creating texture: %v", err)
+	}
+	t.Cleanup(func() { texture.Destroy() })
+	return texture
+}
+
+// setNullTexture makes td draw shapes with tex instead of its font texture.
+func setNullTexture(td *TestDriver, tex *sdl.Texture) {
+	td.null.Texture = TextureHandle(tex)
+	td.rebuildConvertConfig()
+}
+
+func TestRenderFrameRestoresRendererStateOnError(t *testing.T) {
+	td := newTestDriver(t)
+	// drawing the button's background with a texture of another renderer makes
+	// RenderGeometry fail in the middle of the draw loop
+	setNullTexture(td, foreignTexture(t))
+	clipRect := sdl.Rect{X: 5, Y: 6, W: 70, H: 80}
+	viewport := sdl.Rect{X: 1, Y: 2, W: 150, H: 90}
+	if err := td.Renderer().SetViewport(&viewport); err != nil {
+		t.Fatal(err)
+	}
+	if err := td.Renderer().SetClipRect(&clipRect); err != nil {
+		t.Fatal(err)
+	}
+	if err := td.SetGUIViewport(sdl.Rect{X: 10, Y: 10, W: 100, H: 50}); err != nil {
+		t.Fatal(err)
+	}
+	if err := td.Frame(buildButton); err == nil {
+		t.Fatal("frame rendered with a foreign texture did not fail")
+	}
+	if got := td.Renderer().GetClipRect(); got != clipRect {
+		t.Errorf("clip rect after failed frame is %v, want %v", got, clipRect)
+	}
+	if got := td.Renderer().GetViewport(); got != viewport {
+		t.Errorf("viewport after failed frame is %v, want %v", got, viewport)
+	}
+}
+
+func TestSetRenderScaleBeforeInit(t *testing.T) {
+	nan := float32(math.NaN())
+	tests := []struct {
+		scale     float32
+		valid     bool
+		wantScale float32
+		wantAuto  bool
+	}{
+		{0, true, 1, true},
+		{2, true, 2, false},
+		{5, true, 5, false},
+		{-1, false, 1, false},
+		{5.5, false, 1, false},
+		{nan, false, 1, false},
+	}
+	for _, test := range tests {
+		d := newUninitializedDriver()
+		err := d.SetRenderScale(test.scale)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("SetRenderScale(%g) returned %v, want valid=%t", test.scale, err, test.valid)
+		}
+		if d.RenderScale() != test.wantScale || d.autoScale != test.wantAuto {
+			t.Errorf("after SetRenderScale(%g), scale is %g and auto is %t, want %g and %t",
+				test.scale, d.RenderScale(), d.autoScale, test.wantScale, test.wantAuto)
+		}
+	}
+}
+
+func TestSetRenderScaleAuto(t *testing.T) {
+	td := newTestDriver(t)
+	if err := td.SetRenderScale(3); err != nil {
+		t.Fatal(err)
+	}
+	// the software renderer's output is the size of the window
+	if err := td.SetRenderScale(0); err != nil {
+		t.Fatal(err)
+	}
+	if got := td.RenderScale(); got != 1 {
+		t.Errorf("automatic render scale is %g, want 1", got)
+	}
+}
+
+func TestFrameStartRetryAfterError(t *testing.T) {
+	td := newTestDriver(t)
+	listenerErr := errors.New("listener failed")
+	td.eventListener = func(event sdl.Event, eventType EventType, usedByNuklear bool) error {
+		return listenerErr
+	}
+	if err := td.MoveMouse(10, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := td.Frame(buildButton); !errors.Is(err, listenerErr) {
+		t.Fatalf("first frame returned %v, want %v", err, listenerErr)
+	}
+	td.eventListener = nil
+	for i := 0; i < 2; i++ {
+		if err := td.Frame(buildButton); err != nil {
+			t.Fatalf("frame %d after failed frame: %v", i, err)
+		}
+	}
+}
+
+func TestErrNotInitialized(t *testing.T) {
+	d := newUninitializedDriver()
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"FrameStart", d.FrameStart},
+		{"FrameEnd", d.FrameEnd},
+		{"ClearContext", d.ClearContext},
+		{"BeginInput", d.BeginInput},
+		{"EndInput", d.EndInput},
+		{"Present", d.Present},
+		{"RenderFrame", func() error {
+			return d.RenderFrame(nil, nil, nil)
+		}},
+		{"ForEachDrawCommand", func() error {
+			return d.ForEachDrawCommand(func(cmd *nk.DrawCommand, vertices []sdl.Vertex, indices []int32) error {
+				return nil
+			})
+		}},
+		{"FontAtlasImage", func() error {
+			_, err := d.FontAtlasImage()
+			return err
+		}},
+		{"AddLayer", func() error {
+			_, err := d.AddLayer(false)
+			return err
+		}},
+	}
+	for _, test := range tests {
+		if err := test.call(); err != ErrNotInitialized {
+			t.Errorf("%s returned %v, want ErrNotInitialized", test.name, err)
+		}
+	}
+}
+
+func TestErrAlreadyInitialized(t *testing.T) {
+	td := newTestDriver(t)
+	if err := td.Init(); err != ErrAlreadyInitialized {
+		t.Errorf("second Init returned %v, want ErrAlreadyInitialized", err)
+	}
+	// the failed Init must not have torn down the Driver
+	if err := td.Frame(buildButton); err != nil {
+		t.Errorf("frame after second Init: %v", err)
+	}
+}
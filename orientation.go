@@ -0,0 +1,128 @@
+package nksdl
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Rotation is a clockwise rotation of the GUI by a multiple of 90 degrees.
+type Rotation int32
+
+const (
+	Rotate0 Rotation = iota
+	Rotate90
+	Rotate180
+	Rotate270
+	rotationCount
+)
+
+// orientation maps between Nuklear coordinates and the coordinates of the
+// area the GUI is drawn in, which is w by h.
+type orientation struct {
+	rotation Rotation
+	flip     sdl.RendererFlip
+	w, h     int32 // size of the area the GUI is drawn in
+}
+
+// identity returns true if o does not transform coordinates.
+func (o *orientation) identity() bool {
+	return o.rotation == Rotate0 && o.flip == sdl.FLIP_NONE
+}
+
+// toScreen maps the point (x, y) from Nuklear coordinates to the area the GUI
+// is drawn in.
+func (o *orientation) toScreen(x, y float32) (float32, float32) {
+	w, h := float32(o.w), float32(o.h)
+	switch o.rotation {
+	case Rotate90:
+		x, y = w-y, x
+	case Rotate180:
+		x, y = w-x, h-y
+	case Rotate270:
+		x, y = y, h-x
+	}
+	if o.flip&sdl.FLIP_HORIZONTAL != 0 {
+		x = w - x
+	}
+	if o.flip&sdl.FLIP_VERTICAL != 0 {
+		y = h - y
+	}
+	return x, y
+}
+
+// toGUI maps the point (x, y) from the area the GUI is drawn in to Nuklear
+// coordinates, undoing toScreen.
+func (o *orientation) toGUI(x, y int32) (int32, int32) {
+	if o.flip&sdl.FLIP_HORIZONTAL != 0 {
+		x = o.w - x
+	}
+	if o.flip&sdl.FLIP_VERTICAL != 0 {
+		y = o.h - y
+	}
+	switch o.rotation {
+	case Rotate90:
+		x, y = y, o.w-x
+	case Rotate180:
+		x, y = o.w-x, o.h-y
+	case Rotate270:
+		x, y = o.h-y, x
+	}
+	return x, y
+}
+
+// rectToScreen maps rect from Nuklear coordinates to the area the GUI is drawn
+// in.
+func (o *orientation) rectToScreen(rect sdl.Rect) sdl.Rect {
+	x1, y1 := o.toScreen(float32(rect.X), float32(rect.Y))
+	x2, y2 := o.toScreen(float32(rect.X+rect.W), float32(rect.Y+rect.H))
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	return sdl.Rect{X: int32(x1), Y: int32(y1), W: int32(x2 - x1), H: int32(y2 - y1)}
+}
+
+// Orientation returns the rotation and flip applied to the GUI.
+func (d *Driver) Orientation() (Rotation, sdl.RendererFlip) {
+	return d.eventHandler.orient.rotation, d.eventHandler.orient.flip
+}
+
+// SetOrientation rotates the GUI clockwise by rotation and then flips it as
+// given by flip, e.g. for a display mounted in portrait orientation or viewed
+// through a mirror. Mouse input is transformed the opposite way, so that it
+// lands where it appears to. The GUI is drawn in the same area as without a
+// transformation, i.e. the whole window or the GUI viewport (see
+// SetGUIViewport), so when it is rotated by 90 or 270 degrees, the width and
+// height of the area in Nuklear coordinates are swapped, and the application
+// should lay out its windows accordingly.
+//
+// The transformation is applied to the vertices of the GUI and the clip
+// rectangles of its draw commands while rendering, which costs little next to
+// converting the draw commands. Unlike rendering to a texture and copying it
+// rotated with SDL_RenderCopyEx, this needs no extra texture or full-window
+// copy per frame, and keeps the GUI sharp. Only the GUI is transformed: the
+// application's own rendering and the shapes of the software and remote
+// cursors are not, and ForEachDrawCommand reports the untransformed geometry.
+func (d *Driver) SetOrientation(rotation Rotation, flip sdl.RendererFlip) error {
+	if rotation < 0 || rotation >= rotationCount {
+		return fmt.Errorf("rotation %d is invalid", rotation)
+	} else if flip&^(sdl.FLIP_HORIZONTAL|sdl.FLIP_VERTICAL) != 0 {
+		return fmt.Errorf("flip %#x is invalid", flip)
+	}
+	d.eventHandler.orient.rotation = rotation
+	d.eventHandler.orient.flip = flip
+	return nil
+}
+
+// orientVertices maps vertices from Nuklear coordinates to the area the GUI is
+// drawn in.
+func (d *Driver) orientVertices(vertices []sdl.Vertex) {
+	o := &d.eventHandler.orient
+	for i := range vertices {
+		v := &vertices[i].Position
+		v.X, v.Y = o.toScreen(v.X, v.Y)
+	}
+}
@@ -0,0 +1,241 @@
+package nksdl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// IdleOpts sets options for idle detection. When the Driver receives no input
+// events for the idle timeout, it drops to a low frame rate to save power, by
+// waiting for events in FrameStart. It snaps back to the full frame rate as
+// soon as an event arrives.
+type IdleOpts struct {
+	// Timeout is how long the Driver must go without input before it becomes
+	// idle. A Timeout of zero disables idle detection.
+	Timeout time.Duration
+	// FPS is the frame rate while idle. It must be positive if Timeout is
+	// non-zero.
+	FPS int
+}
+
+// idleState tracks idle detection and other reasons to lower the frame rate.
+type idleState struct {
+	opts         IdleOpts
+	lastActivity time.Time // time of the last input event
+	unfocusedFPS int       // frame rate while unfocused, see SetUnfocusedFPS
+	focused      bool      // whether the window has input focus
+	redraw       bool      // whether the next frame must not wait, see Invalidate
+	redrawAt     time.Time // time of the next scheduled redraw, or zero
+}
+
+func (d *Driver) IdleOpts() IdleOpts {
+	return d.idle.opts
+}
+
+// SetIdleOpts sets the options for idle detection.
+func (d *Driver) SetIdleOpts(opts IdleOpts) error {
+	if opts.Timeout < 0 {
+		return fmt.Errorf("idle Timeout(%s) is negative", opts.Timeout)
+	} else if opts.Timeout > 0 && opts.FPS <= 0 {
+		return fmt.Errorf("idle FPS(%d) is not positive", opts.FPS)
+	}
+	d.idle.opts = opts
+	return nil
+}
+
+// Idle returns true if idle detection is enabled and the Driver has received
+// no input for the idle timeout.
+func (d *Driver) Idle() bool {
+	return d.idleAt(d.now())
+}
+
+func (d *Driver) idleAt(now time.Time) bool {
+	if d.idle.opts.Timeout == 0 || d.idle.lastActivity.IsZero() {
+		return false
+	}
+	return now.Sub(d.idle.lastActivity) >= d.idle.opts.Timeout
+}
+
+func (d *Driver) UnfocusedFPS() int {
+	return d.idle.unfocusedFPS
+}
+
+// SetUnfocusedFPS caps the frame rate while the window does not have input
+// focus, e.g. to save power in a tool that runs in the background. An fps of 0
+// leaves the frame rate unchanged, so that e.g. a dashboard keeps animating,
+// while an fps of -1 pauses rendering entirely until an event arrives. The
+// full frame rate resumes as soon as the window regains focus. If the Driver
+// is also idle (see SetIdleOpts), the lower of the two frame rates applies.
+func (d *Driver) SetUnfocusedFPS(fps int) error {
+	if fps < -1 {
+		return fmt.Errorf("unfocused FPS(%d) is out of bounds", fps)
+	}
+	d.idle.unfocusedFPS = fps
+	return nil
+}
+
+// markActive records activity which keeps the Driver from becoming idle.
+func (d *Driver) markActive() {
+	d.idle.lastActivity = d.now()
+}
+
+// waitIdle waits for an event, if the Driver is idle or unfocused, until the
+// next frame is due at the reduced frame rate. It returns the event received,
+// if any, which must be handled before any further events are polled.
+func (d *Driver) waitIdle() sdl.Event {
+	now := d.now()
+	if d.frameCount == 0 || d.fontLoad != nil {
+		return nil
+	} else if d.idle.redraw {
+		d.idle.redraw = false
+		return nil
+	}
+	fps := d.reducedFPS(now)
+	if fps == 0 {
+		// frames are not delayed, so any scheduled redraw that is due happens
+		if !d.idle.redrawAt.IsZero() && !now.Before(d.idle.redrawAt) {
+			d.idle.redrawAt = time.Time{}
+		}
+		return nil
+	}
+	var deadline time.Time
+	if fps > 0 {
+		deadline = d.frameTime.Add(time.Second / time.Duration(fps))
+	}
+	redrawAt := d.idle.redrawAt
+	if !redrawAt.IsZero() && (deadline.IsZero() || redrawAt.Before(deadline)) {
+		deadline = redrawAt
+	}
+	if deadline.IsZero() {
+		return sdl.WaitEvent()
+	}
+	wait := deadline.Sub(now) / time.Millisecond
+	var event sdl.Event
+	if wait > 0 {
+		event = sdl.WaitEventTimeout(int(wait))
+	}
+	if event == nil && deadline.Equal(redrawAt) {
+		d.idle.redrawAt = time.Time{}
+	}
+	return event
+}
+
+// Invalidate makes the next frame render without waiting, even if the Driver
+// is idle or unfocused and would otherwise wait for an event or for its
+// reduced frame rate (see SetIdleOpts and SetUnfocusedFPS), e.g. to show a
+// change made by a timer or a network update. Invalidate must be called from
+// the render thread, i.e. the main OS thread, typically between frames; to
+// wake the Driver from another goroutine, call Wake instead.
+func (d *Driver) Invalidate() {
+	d.idle.redraw = true
+}
+
+// RequestRedrawAt schedules a frame to render no later than at time t, even if
+// the Driver is idle or unfocused and would otherwise wait longer, e.g. for the
+// next step of an animation. If t has already passed, the next frame renders
+// without waiting. Only the earliest scheduled redraw is kept, so an
+// application with several schedules should request the next of them after
+// every frame. The same thread constraints apply as to Invalidate.
+func (d *Driver) RequestRedrawAt(t time.Time) {
+	if d.idle.redrawAt.IsZero() || t.Before(d.idle.redrawAt) {
+		d.idle.redrawAt = t
+	}
+}
+
+// reducedFPS returns the frame rate to which the Driver drops because it is
+// idle or unfocused, 0 if it runs at the full frame rate, or -1 if rendering
+// is paused until an event arrives.
+func (d *Driver) reducedFPS(now time.Time) int {
+	fps := 0
+	if d.idleAt(now) {
+		fps = d.idle.opts.FPS
+	}
+	if !d.idle.focused && d.idle.unfocusedFPS != 0 {
+		if d.idle.unfocusedFPS < 0 {
+			return -1
+		} else if fps == 0 || d.idle.unfocusedFPS < fps {
+			fps = d.idle.unfocusedFPS
+		}
+	}
+	return fps
+}
+
+// SetIdleWork sets a callback for doing background work, e.g. loading or
+// indexing, in the spare time of each frame. The callback is invoked by
+// FrameEnd after the frame has been rendered and before it is presented, with
+// the time remaining until the next frame is due. The next frame is due one
+// frame interval after the start of the current frame, where the frame
+// interval is that of the display's refresh rate (see DisplayRefreshRate), or
+// of the reduced frame rate while idle or unfocused (see SetIdleOpts and
+// SetUnfocusedFPS). The budget is zero if the frame is already over budget, in
+// which case the callback should do little or nothing. The callback runs on
+// the render thread, i.e. the main OS thread, so it blocks the frame for as
+// long as it runs. A nil callback disables this behavior.
+func (d *Driver) SetIdleWork(callback func(budget time.Duration)) {
+	d.idleWork = callback
+}
+
+// runIdleWork invokes the idle work callback, if any, with the time remaining
+// until the next frame is due.
+func (d *Driver) runIdleWork() {
+	if d.idleWork == nil {
+		return
+	}
+	now := d.now()
+	fps := d.reducedFPS(now)
+	if fps <= 0 {
+		fps = d.DisplayRefreshRate()
+	}
+	budget := d.frameTime.Add(time.Second / time.Duration(fps)).Sub(now)
+	if budget < 0 {
+		budget = 0
+	}
+	d.idleWork(budget)
+}
+
+// frameTimeHistory is a ring buffer of recent frame times, in milliseconds.
+type frameTimeHistory struct {
+	times []float32
+	next  int  // index at which the next frame time is stored
+	full  bool // whether every element of times has been stored
+}
+
+// SetFrameTimeHistorySize sets how many of the most recent frame times are kept
+// for FrameTimeHistory. A size of 0, the default, keeps none. Changing the size
+// discards the frame times kept so far.
+func (d *Driver) SetFrameTimeHistorySize(size int) error {
+	if size < 0 {
+		return fmt.Errorf("frame time history size(%d) is negative", size)
+	}
+	d.frameTimes = frameTimeHistory{times: make([]float32, size)}
+	return nil
+}
+
+// FrameTimeHistory returns the most recent frame times, i.e. the values of
+// DeltaTime in milliseconds, from oldest to newest, e.g. for plotting a frame
+// time graph with Nuklear. At most as many frame times are kept as set by
+// SetFrameTimeHistorySize. The returned slice is a copy.
+func (d *Driver) FrameTimeHistory() []float32 {
+	h := &d.frameTimes
+	if !h.full {
+		return append([]float32(nil), h.times[:h.next]...)
+	}
+	history := make([]float32, 0, len(h.times))
+	history = append(history, h.times[h.next:]...)
+	return append(history, h.times[:h.next]...)
+}
+
+// record records the frame time dt.
+func (h *frameTimeHistory) record(dt time.Duration) {
+	if len(h.times) == 0 {
+		return
+	}
+	h.times[h.next] = float32(dt) / float32(time.Millisecond)
+	h.next++
+	if h.next == len(h.times) {
+		h.next = 0
+		h.full = true
+	}
+}
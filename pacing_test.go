@@ -0,0 +1,56 @@
+package nksdl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFrameTimeHistory(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		frames int // frames recorded, taking 1ms, 2ms, ... in turn
+		want   []float32
+	}{
+		{"disabled", 0, 3, nil},
+		{"empty", 3, 0, nil},
+		{"partial", 3, 2, []float32{1, 2}},
+		{"full", 3, 3, []float32{1, 2, 3}},
+		{"wrapped", 3, 4, []float32{2, 3, 4}},
+		{"wrapped twice", 3, 7, []float32{5, 6, 7}},
+		{"single", 1, 2, []float32{2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := newUninitializedDriver()
+			if err := d.SetFrameTimeHistorySize(test.size); err != nil {
+				t.Fatal(err)
+			}
+			for i := 1; i <= test.frames; i++ {
+				d.frameTimes.record(time.Duration(i) * time.Millisecond)
+			}
+			got := d.FrameTimeHistory()
+			if len(got) != len(test.want) || len(got) != 0 && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetFrameTimeHistorySize(t *testing.T) {
+	d := newUninitializedDriver()
+	if err := d.SetFrameTimeHistorySize(-1); err == nil {
+		t.Error("SetFrameTimeHistorySize(-1) succeeded")
+	}
+	if err := d.SetFrameTimeHistorySize(2); err != nil {
+		t.Fatal(err)
+	}
+	d.frameTimes.record(time.Millisecond)
+	if err := d.SetFrameTimeHistorySize(2); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.FrameTimeHistory(); len(got) != 0 {
+		t.Errorf("got %v after changing the size, want no frame times", got)
+	}
+}
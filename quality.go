@@ -0,0 +1,168 @@
+package nksdl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kbolino/go-nk"
+)
+
+// AntiAliasing returns whether lines and shapes are anti-aliased.
+func (d *Driver) AntiAliasing() (line, shape nk.AntiAliasing) {
+	return d.convertOpts.LineAA, d.convertOpts.ShapeAA
+}
+
+// SetAntiAliasing sets whether lines and shapes are anti-aliased, overriding
+// the initial ConvertOpts. Turning anti-aliasing off reduces the number of
+// vertices rendered, e.g. for a low quality mode on slow hardware.
+// SetAntiAliasing must be called after Init, and takes effect from the next
+// frame rendered.
+func (d *Driver) SetAntiAliasing(line, shape nk.AntiAliasing) error {
+	if d.context == nil {
+		return errors.New("anti-aliasing cannot be set before Init")
+	} else if err := checkAntiAliasing(line); err != nil {
+		return fmt.Errorf("line anti-aliasing: %w", err)
+	} else if err := checkAntiAliasing(shape); err != nil {
+		return fmt.Errorf("shape anti-aliasing: %w", err)
+	}
+	d.convertOpts.LineAA, d.convertOpts.ShapeAA = line, shape
+	d.rebuildConvertConfig()
+	return nil
+}
+
+func checkAntiAliasing(aa nk.AntiAliasing) error {
+	if aa != nk.AntiAliasingOff && aa != nk.AntiAliasingOn {
+		return fmt.Errorf("value %d is invalid", aa)
+	}
+	return nil
+}
+
+// maxSegmentCount is the largest segment count accepted by SetSegmentCounts.
+// Nuklear itself sets no upper bound, but every segment costs vertices, and
+// beyond this many segments curves are no smoother at any realistic size.
+const maxSegmentCount = 256
+
+// SegmentCounts returns the number of segments used to draw circles, curves,
+// and arcs.
+func (d *Driver) SegmentCounts() (circle, curve, arc uint32) {
+	return d.convertOpts.CircleSegmentCount, d.convertOpts.CurveSegmentCount, d.convertOpts.ArcSegmentCount
+}
+
+// SetSegmentCounts sets the number of segments used to draw circles, curves,
+// and arcs, overriding the initial ConvertOpts. Fewer segments trade
+// smoothness for performance, e.g. to save power on battery. Each count must
+// be from 1 to 256; Nuklear's default is nk.DefaultSegmentCount.
+// SetSegmentCounts must be called after Init, and takes effect from the next
+// frame rendered.
+func (d *Driver) SetSegmentCounts(circle, curve, arc uint32) error {
+	if d.context == nil {
+		return errors.New("segment counts cannot be set before Init")
+	} else if err := checkSegmentCount(circle); err != nil {
+		return fmt.Errorf("circle segment count: %w", err)
+	} else if err := checkSegmentCount(curve); err != nil {
+		return fmt.Errorf("curve segment count: %w", err)
+	} else if err := checkSegmentCount(arc); err != nil {
+		return fmt.Errorf("arc segment count: %w", err)
+	}
+	d.convertOpts.CircleSegmentCount = circle
+	d.convertOpts.CurveSegmentCount = curve
+	d.convertOpts.ArcSegmentCount = arc
+	d.rebuildConvertConfig()
+	return nil
+}
+
+func checkSegmentCount(count uint32) error {
+	if count == 0 || count > maxSegmentCount {
+		return fmt.Errorf("value %d is out of bounds", count)
+	}
+	return nil
+}
+
+// maxClipMargin is the largest magnitude of the clip margin, in pixels.
+const maxClipMargin = 16
+
+func (d *Driver) ClipMargin() int32 {
+	return d.clipMargin
+}
+
+// SetClipMargin sets a margin by which the clip rectangle of every draw command
+// is expanded on each side, or contracted if the margin is negative, e.g. to
+// keep the outermost pixel of widget borders from being clipped, which can
+// happen due to rounding at some render scales. The margin is in pixels of the
+// renderer output, i.e. after the render scale is applied. Since SDL clip
+// rectangles are in unscaled coordinates, the margin is rounded away from zero
+// to a whole number of those, so at a render scale of 2, a margin of 1 expands
+// the clip rectangle by 2 pixels. The default margin is 0, which leaves clip
+// rectangles as Nuklear computes them.
+func (d *Driver) SetClipMargin(margin int32) error {
+	if margin < -maxClipMargin || margin > maxClipMargin {
+		return fmt.Errorf("clip margin(%d) is out of bounds", margin)
+	}
+	d.clipMargin = margin
+	return nil
+}
+
+// clipMarginScaled returns the clip margin in the coordinates of the renderer
+// before the render scale is applied.
+func (d *Driver) clipMarginScaled() (x, y int32) {
+	if d.clipMargin == 0 {
+		return 0, 0
+	}
+	scaleX, scaleY := d.renderer.GetScale()
+	return scaleClipMargin(d.clipMargin, scaleX), scaleClipMargin(d.clipMargin, scaleY)
+}
+
+// scaleClipMargin converts margin from output pixels to a whole number of
+// coordinates before the given render scale is applied, rounding away from
+// zero. A scale which is not positive is treated as 1.
+func scaleClipMargin(margin int32, scale float32) int32 {
+	if scale <= 0 {
+		return margin
+	}
+	m := int32(math.Ceil(math.Abs(float64(margin) / float64(scale))))
+	if margin < 0 {
+		return -m
+	}
+	return m
+}
+
+func (d *Driver) MaxIndicesPerDraw() int {
+	return d.maxIndices
+}
+
+// SetMaxIndicesPerDraw sets the largest number of indices, i.e. three times
+// the number of triangles, passed to a single call to SDL_RenderGeometry. Draw
+// commands with more indices are split into several calls, with the same clip
+// rectangle and texture, so that a complex frame, e.g. one with a large plot
+// or a long text, still renders with a backend that fails on large calls. The
+// limit is rounded down to a whole number of triangles, and must be at least
+// 3, unless it is 0, the default, which does not split draw commands.
+//
+// SDL offers no way to query such a limit, and none of its built-in renderers
+// has a fixed one: each queues the geometry of every call in memory it grows
+// as needed. A limit is only needed for backends which fail on large calls
+// anyway, e.g. because of a custom or patched renderer, or a driver that
+// cannot allocate a large enough vertex buffer; a limit of a few tens of
+// thousands of indices is then a reasonable choice.
+func (d *Driver) SetMaxIndicesPerDraw(maxIndices int) error {
+	if maxIndices < 0 || (maxIndices > 0 && maxIndices < 3) {
+		return fmt.Errorf("maxIndices(%d) is out of bounds", maxIndices)
+	}
+	d.maxIndices = maxIndices - maxIndices%3
+	return nil
+}
+
+// rebuildConvertConfig replaces the convert config with a new one created from
+// the current convert options.
+func (d *Driver) rebuildConvertConfig() {
+	convertConf := d.nkDriver.CreateConvertConfig(
+		d.convertOpts,
+		vertexLayout,
+		uint32(vertexSize),
+		uint32(vertexAlignment),
+		d.null,
+	)
+	d.convertConf.Free()
+	d.convertConf = convertConf
+}
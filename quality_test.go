@@ -0,0 +1,137 @@
+package nksdl
+
+import (
+	"testing"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// newUninitializedDriver returns a Driver on which Init has not been called.
+func newUninitializedDriver() *Driver {
+	return NewDriver(&DefaultSDLDriver{}, &DefaultNkDriver{}, DefaultBindings, nil)
+}
+
+func TestSetAntiAliasingBeforeInit(t *testing.T) {
+	d := newUninitializedDriver()
+	line, shape := d.AntiAliasing()
+	if err := d.SetAntiAliasing(nk.AntiAliasingOn, nk.AntiAliasingOn); err == nil {
+		t.Error("SetAntiAliasing succeeded before Init")
+	}
+	if gotLine, gotShape := d.AntiAliasing(); gotLine != line || gotShape != shape {
+		t.Errorf("anti-aliasing changed to %d, %d despite failing", gotLine, gotShape)
+	}
+}
+
+func TestSetSegmentCountsBeforeInit(t *testing.T) {
+	d := newUninitializedDriver()
+	if err := d.SetSegmentCounts(8, 8, 8); err == nil {
+		t.Error("SetSegmentCounts succeeded before Init")
+	}
+	if circle, curve, arc := d.SegmentCounts(); circle == 8 || curve == 8 || arc == 8 {
+		t.Errorf("segment counts changed to %d, %d, %d despite failing", circle, curve, arc)
+	}
+}
+
+func TestScaleClipMargin(t *testing.T) {
+	tests := []struct {
+		margin int32
+		scale  float32
+		want   int32
+	}{
+		{0, 2, 0},
+		{1, 1, 1},
+		{1, 2, 1},
+		{3, 2, 2},
+		{4, 2, 2},
+		{-3, 2, -2},
+		{-1, 0.5, -2},
+		{2, 1.5, 2},
+		{5, 0, 5},
+		{5, -1, 5},
+	}
+	for _, test := range tests {
+		if got := scaleClipMargin(test.margin, test.scale); got != test.want {
+			t.Errorf("scaleClipMargin(%d, %g) = %d, want %d", test.margin, test.scale, got, test.want)
+		}
+	}
+}
+
+func TestSetClipMargin(t *testing.T) {
+	tests := []struct {
+		margin int32
+		valid  bool
+	}{
+		{0, true},
+		{1, true},
+		{-1, true},
+		{maxClipMargin, true},
+		{-maxClipMargin, true},
+		{maxClipMargin + 1, false},
+		{-maxClipMargin - 1, false},
+	}
+	for _, test := range tests {
+		d := newUninitializedDriver()
+		err := d.SetClipMargin(test.margin)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("SetClipMargin(%d) returned %v, want valid=%t", test.margin, err, test.valid)
+		} else if valid && d.ClipMargin() != test.margin {
+			t.Errorf("SetClipMargin(%d) set the margin to %d", test.margin, d.ClipMargin())
+		} else if !valid && d.ClipMargin() != 0 {
+			t.Errorf("SetClipMargin(%d) changed the margin to %d despite failing", test.margin, d.ClipMargin())
+		}
+	}
+}
+
+func TestSetMaxIndicesPerDraw(t *testing.T) {
+	tests := []struct {
+		maxIndices int
+		want       int
+		valid      bool
+	}{
+		{0, 0, true},
+		{3, 3, true},
+		{4, 3, true},
+		{5, 3, true},
+		{6, 6, true},
+		{30001, 30000, true},
+		{1, 0, false},
+		{2, 0, false},
+		{-3, 0, false},
+	}
+	for _, test := range tests {
+		d := newUninitializedDriver()
+		err := d.SetMaxIndicesPerDraw(test.maxIndices)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("SetMaxIndicesPerDraw(%d) returned %v, want valid=%t", test.maxIndices, err, test.valid)
+		} else if got := d.MaxIndicesPerDraw(); got != test.want {
+			t.Errorf("SetMaxIndicesPerDraw(%d) set the limit to %d, want %d", test.maxIndices, got, test.want)
+		}
+	}
+}
+
+func TestMaxIndicesPerDrawRendersSame(t *testing.T) {
+	td := newTestDriver(t)
+	frame := func() []sdl.Color {
+		if err := td.Frame(buildButton); err != nil {
+			t.Fatal(err)
+		}
+		var pixels []sdl.Color
+		for y := int32(0); y < 100; y++ {
+			for x := int32(0); x < 200; x++ {
+				pixels = append(pixels, td.PixelAt(x, y))
+			}
+		}
+		return pixels
+	}
+	want := frame()
+	if err := td.SetMaxIndicesPerDraw(3); err != nil {
+		t.Fatal(err)
+	}
+	got := frame()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pixel (%d, %d) is %v when split, want %v", i%200, i/200, got[i], want[i])
+		}
+	}
+}
@@ -3,6 +3,7 @@ package nksdl
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/veandco/go-sdl2/sdl"
 )
@@ -20,8 +21,16 @@ type SDLDriver interface {
 type DefaultSDLDriver struct {
 	// InitFlags contains flags to pass to sdl.Init.
 	InitFlags uint32
-	// Hints contains hint keys and values to pass to sdl.SetHint.
+	// DPI contains options for high-DPI support, whose hints are set before
+	// Hints and OrderedHints, so that those can override them.
+	DPI DPIOpts
+	// Hints contains hint keys and values to pass to sdl.SetHint. They are set
+	// in order of their keys, before OrderedHints.
 	Hints map[string]string
+	// OrderedHints contains hints to pass to sdl.SetHint in the given order,
+	// after Hints, for hints whose effect depends on the order in which they
+	// are set. A hint set later overrides the same hint set earlier.
+	OrderedHints []Hint
 	// Window contains options for creating the window.
 	Window WindowOpts
 	// Render contains options for creating the renderer.
@@ -30,37 +39,93 @@ type DefaultSDLDriver struct {
 
 var _ SDLDriver = &DefaultSDLDriver{}
 
+// Hint is an SDL hint key and value.
+type Hint struct {
+	Key, Value string
+}
+
+// InitSDL sets the hints and then initializes SDL. All hints are set before
+// sdl.Init, since some hints, such as SDL_VIDEODRIVER and
+// SDL_FRAMEBUFFER_ACCELERATION, are only read when a subsystem is initialized
+// and have no effect if set later. Others are read when the window or renderer
+// is created, e.g. SDL_RENDER_DRIVER, or whenever they are used, and are also
+// set in time. A hint that is overridden by an environment
+// variable is logged as a warning.
 func (d *DefaultSDLDriver) InitSDL() error {
-	if err := sdl.Init(d.InitFlags); err != nil {
-		return err
+	dpiHints, err := d.DPI.hints()
+	if err != nil {
+		return fmt.Errorf("invalid DPI options: %w", err)
 	}
-	for key, value := range d.Hints {
-		sdl.SetHint(key, value)
+	keys := make([]string, 0, len(d.Hints))
+	for key := range d.Hints {
+		keys = append(keys, key)
 	}
-	return nil
+	sort.Strings(keys)
+	hints := make([]Hint, 0, len(dpiHints)+len(keys)+len(d.OrderedHints))
+	hints = append(hints, dpiHints...)
+	for _, key := range keys {
+		hints = append(hints, Hint{Key: key, Value: d.Hints[key]})
+	}
+	hints = append(hints, d.OrderedHints...)
+	for _, hint := range hints {
+		if hint.Key == "" {
+			return errors.New("hint key is empty")
+		}
+		if !sdl.SetHint(hint.Key, hint.Value) {
+			sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "hint %s was not set to %q", hint.Key, hint.Value)
+		}
+	}
+	return sdl.Init(d.InitFlags)
 }
 
 func (d *DefaultSDLDriver) CreateWindow() (*sdl.Window, error) {
-	window, err := sdl.CreateWindow(d.Window.Title, d.Window.PosX, d.Window.PosY, d.Window.Width, d.Window.Height,
-		d.Window.Flags)
+	if err := d.Window.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid window options: %w", err)
+	}
+	width, height := d.Window.Width, d.Window.Height
+	if d.Window.ClampToDisplay {
+		bounds, err := sdl.GetDisplayUsableBounds(0)
+		if err != nil {
+			return nil, fmt.Errorf("getting usable bounds of primary display: %w", err)
+		}
+		if width > bounds.W {
+			width = bounds.W
+		}
+		if height > bounds.H {
+			height = bounds.H
+		}
+	}
+	flags := d.Window.Flags
+	if d.DPI.HighDPI {
+		flags |= sdl.WINDOW_ALLOW_HIGHDPI
+	}
+	window, err := sdl.CreateWindow(d.Window.Title, d.Window.PosX, d.Window.PosY, width, height, flags)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("creating %dx%d window (flags %#x): %w", width, height, flags, err)
+	}
+	if d.Window.Opacity != 0 {
+		if err := window.SetWindowOpacity(d.Window.Opacity); err != nil {
+			sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "setting window opacity to %g: %s", d.Window.Opacity, err.Error())
+		}
 	}
-	return window, err
+	return window, nil
 }
 
 func (d *DefaultSDLDriver) CreateRenderer(window *sdl.Window) (*sdl.Renderer, error) {
+	if d.Render.ScaleQuality != ScaleQualityDefault {
+		value, ok := scaleQualityHints[d.Render.ScaleQuality]
+		if !ok {
+			return nil, fmt.Errorf("scale quality %d is invalid", d.Render.ScaleQuality)
+		}
+		if !sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, value) {
+			sdl.LogWarn(sdl.LOG_CATEGORY_APPLICATION, "hint %s was not set to %q", sdl.HINT_RENDER_SCALE_QUALITY, value)
+		}
+	}
 	renderDriver := -1
 	if len(d.Render.Drivers) != 0 {
-		numRenderDrivers, err := sdl.GetNumRenderDrivers()
+		infos, err := AvailableRenderDrivers()
 		if err != nil {
-			return nil, fmt.Errorf("getting number of render drivers: %w", err)
-		}
-		infos := make([]sdl.RendererInfo, numRenderDrivers)
-		for i := 0; i < numRenderDrivers; i++ {
-			if _, err := sdl.GetRenderDriverInfo(i, &infos[i]); err != nil {
-				return nil, fmt.Errorf("getting info for render driver %d: %w", i, err)
-			}
+			return nil, err
 		}
 	preferenceLoop:
 		for _, driver := range d.Render.Drivers {
@@ -72,12 +137,12 @@ func (d *DefaultSDLDriver) CreateRenderer(window *sdl.Window) (*sdl.Renderer, er
 			}
 		}
 		if renderDriver < 0 {
-			return nil, errors.New("could not find any preferred render driver")
+			return nil, fmt.Errorf("none of the preferred render drivers %q is available", d.Render.Drivers)
 		}
 	}
 	renderer, err := sdl.CreateRenderer(window, renderDriver, d.Render.Flags)
 	if err != nil {
-		return nil, fmt.Errorf("creating renderer: %w", err)
+		return nil, fmt.Errorf("creating renderer (driver index %d, flags %#x): %w", renderDriver, d.Render.Flags, err)
 	}
 	return renderer, nil
 }
@@ -86,6 +151,122 @@ func (d *DefaultSDLDriver) CreateRenderer(window *sdl.Window) (*sdl.Renderer, er
 type RenderOpts struct {
 	Drivers []string
 	Flags   uint32
+	// ScaleQuality sets how textures are filtered when scaled, including the
+	// font atlas. It applies to every texture created after the renderer,
+	// since SDL reads it when each texture is created.
+	ScaleQuality ScaleQuality
+}
+
+// ScaleQuality is the quality of texture filtering when scaling, as set by
+// the SDL_RENDER_SCALE_QUALITY hint. Linear filtering keeps text smooth at
+// fractional render scales, at the cost of slightly blurred glyph edges, while
+// nearest-pixel sampling keeps glyph edges sharp but distorts them unevenly.
+type ScaleQuality int32
+
+const (
+	// ScaleQualityDefault leaves the hint unchanged, which means nearest-pixel
+	// sampling unless the hint has been set elsewhere.
+	ScaleQualityDefault ScaleQuality = iota
+	// ScaleQualityNearest uses nearest-pixel sampling.
+	ScaleQualityNearest
+	// ScaleQualityLinear uses linear filtering.
+	ScaleQualityLinear
+	// ScaleQualityBest uses anisotropic filtering where supported (Direct3D
+	// only), and linear filtering otherwise.
+	ScaleQualityBest
+)
+
+var scaleQualityHints = map[ScaleQuality]string{
+	ScaleQualityNearest: "nearest",
+	ScaleQualityLinear:  "linear",
+	ScaleQualityBest:    "best",
+}
+
+// maxWindowSize is the largest width or height of a window accepted by
+// WindowOpts.Validate, which is beyond the size of any current display.
+const maxWindowSize = 16384
+
+// Hints for DPI awareness on Windows, which were added in SDL 2.24.0 and are
+// not defined by all versions of go-sdl2.
+const (
+	hintWindowsDPIAwareness = "SDL_WINDOWS_DPI_AWARENESS"
+	hintWindowsDPIScaling   = "SDL_WINDOWS_DPI_SCALING"
+)
+
+// DPIOpts sets options for high-DPI support in DefaultSDLDriver. The render
+// scale (see Driver.SetRenderScale) adapts the GUI to whatever pixel density
+// results from these options.
+type DPIOpts struct {
+	// HighDPI, if true, requests a full-resolution drawable on high-DPI
+	// displays, by setting the SDL_VIDEO_HIGHDPI_DISABLED hint to 0 and adding
+	// sdl.WINDOW_ALLOW_HIGHDPI to the window flags. This is what matters on
+	// macOS and iOS, where the window size is in points and the renderer output
+	// is in pixels, and on Wayland. On Windows, it only has an effect together
+	// with Scaling. Without it, such platforms scale up a low-resolution
+	// window, which looks blurry.
+	HighDPI bool
+	// Awareness declares the DPI awareness of the process on Windows, which
+	// must be done before the first window is created. A process which is not
+	// DPI aware is scaled up by Windows on high-DPI displays, which looks
+	// blurry. It has no effect on other platforms, and requires SDL 2.24.0 or
+	// later; older versions of SDL ignore it.
+	Awareness DPIAwareness
+	// Scaling, if true, makes SDL on Windows behave as on macOS when HighDPI
+	// is also true: the window size is in scaled points rather than pixels,
+	// and the renderer output is in pixels. This implies per-monitor V2
+	// awareness. It requires SDL 2.24.0 or later and has no effect on other
+	// platforms.
+	Scaling bool
+}
+
+// DPIAwareness is the DPI awareness of the process on Windows, as set by the
+// SDL_WINDOWS_DPI_AWARENESS hint.
+type DPIAwareness int32
+
+const (
+	// DPIAwarenessDefault leaves the hint unchanged, which means that SDL does
+	// not declare DPI awareness unless the hint has been set elsewhere or the
+	// application's manifest declares it.
+	DPIAwarenessDefault DPIAwareness = iota
+	// DPIAwarenessUnaware lets Windows scale up the window on high-DPI displays.
+	DPIAwarenessUnaware
+	// DPIAwarenessSystem is aware of the DPI of the primary display only, and
+	// lets Windows scale the window on displays with a different DPI.
+	DPIAwarenessSystem
+	// DPIAwarenessPerMonitor is aware of the DPI of each display, falling back
+	// to DPIAwarenessSystem on versions of Windows before 8.1.
+	DPIAwarenessPerMonitor
+	// DPIAwarenessPerMonitorV2 is like DPIAwarenessPerMonitor, but also scales
+	// non-client areas like the title bar, on Windows 10 version 1703 and
+	// later, falling back to DPIAwarenessPerMonitor otherwise. This is the
+	// best choice for most applications.
+	DPIAwarenessPerMonitorV2
+)
+
+var dpiAwarenessHints = map[DPIAwareness]string{
+	DPIAwarenessUnaware:      "unaware",
+	DPIAwarenessSystem:       "system",
+	DPIAwarenessPerMonitor:   "permonitor",
+	DPIAwarenessPerMonitorV2: "permonitorv2",
+}
+
+// hints returns the hints which implement opts.
+func (opts DPIOpts) hints() ([]Hint, error) {
+	var hints []Hint
+	if opts.HighDPI {
+		hints = append(hints, Hint{Key: sdl.HINT_VIDEO_HIGHDPI_DISABLED, Value: "0"})
+	}
+	if opts.Awareness != DPIAwarenessDefault {
+		value, ok := dpiAwarenessHints[opts.Awareness]
+		if !ok {
+			return nil, fmt.Errorf("DPI awareness %d is invalid", opts.Awareness)
+		}
+		hints = append(hints, Hint{Key: hintWindowsDPIAwareness, Value: value})
+	}
+	if opts.Scaling {
+		hints = append(hints, Hint{Key: hintWindowsDPIScaling, Value: "1"})
+	}
+	return hints, nil
 }
 
 // WindowOpts sets options for DefaultSDLDriver.CreateWindow.
@@ -94,4 +275,64 @@ type WindowOpts struct {
 	PosX, PosY    int32
 	Width, Height int32
 	Flags         uint32
+	// ClampToDisplay, if true, shrinks the window as needed to fit within the
+	// usable bounds of the primary display, i.e. excluding taskbars, docks,
+	// and the like.
+	ClampToDisplay bool
+	// Opacity, if not zero, is the opacity of the window, from 0 (fully
+	// transparent) to 1 (fully opaque), which is set after the window is
+	// created. Zero means the default of fully opaque. See
+	// Driver.SetWindowOpacity for platform support.
+	Opacity float32
+}
+
+// Validate returns an error if the window size is not positive or is
+// implausibly large. A window with the sdl.WINDOW_FULLSCREEN_DESKTOP flag
+// fills the display regardless of its size, so its size may also be zero.
+// DefaultSDLDriver.CreateWindow calls Validate before creating the window, so
+// that such mistakes are reported clearly rather than through SDL or the
+// graphics driver.
+func (o WindowOpts) Validate() error {
+	fullscreenDesktop := o.Flags&sdl.WINDOW_FULLSCREEN_DESKTOP == sdl.WINDOW_FULLSCREEN_DESKTOP
+	if fullscreenDesktop && (o.Width < 0 || o.Height < 0) {
+		return fmt.Errorf("window size %dx%d is negative", o.Width, o.Height)
+	} else if !fullscreenDesktop && (o.Width <= 0 || o.Height <= 0) {
+		return fmt.Errorf("window size %dx%d is not positive", o.Width, o.Height)
+	} else if o.Width > maxWindowSize || o.Height > maxWindowSize {
+		return fmt.Errorf("window size %dx%d exceeds maximum size %dx%d",
+			o.Width, o.Height, maxWindowSize, maxWindowSize)
+	} else if o.Opacity != o.Opacity || o.Opacity < 0 || o.Opacity > 1 {
+		// x != x means x is NaN
+		return fmt.Errorf("window Opacity(%g) is out of bounds", o.Opacity)
+	}
+	return nil
+}
+
+// lastSDLError returns the last error reported by SDL, for use after SDL
+// functions which signal failure through their return value alone, e.g. by
+// returning nil.
+func lastSDLError() error {
+	if err := sdl.GetError(); err != nil {
+		return err
+	}
+	return errors.New("unknown SDL error")
+}
+
+// AvailableRenderDrivers returns information about the render drivers that SDL
+// was built with, in the order of their indices, e.g. for listing them in a
+// settings screen. The Name of each can be used in RenderOpts.Drivers. Not
+// every driver returned is necessarily usable on the current system. The
+// returned slice is newly allocated by every call.
+func AvailableRenderDrivers() ([]sdl.RendererInfo, error) {
+	numRenderDrivers, err := sdl.GetNumRenderDrivers()
+	if err != nil {
+		return nil, fmt.Errorf("getting number of render drivers: %w", err)
+	}
+	infos := make([]sdl.RendererInfo, numRenderDrivers)
+	for i := 0; i < numRenderDrivers; i++ {
+		if _, err := sdl.GetRenderDriverInfo(i, &infos[i]); err != nil {
+			return nil, fmt.Errorf("getting info for render driver %d: %w", i, err)
+		}
+	}
+	return infos, nil
 }
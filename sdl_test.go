@@ -0,0 +1,36 @@
+package nksdl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestWindowOptsValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  WindowOpts
+		valid bool
+	}{
+		{"normal", WindowOpts{Width: 800, Height: 600}, true},
+		{"zero", WindowOpts{}, false},
+		{"zero width", WindowOpts{Width: 0, Height: 600}, false},
+		{"negative", WindowOpts{Width: -1, Height: 600}, false},
+		{"too large", WindowOpts{Width: maxWindowSize + 1, Height: 600}, false},
+		{"fullscreen zero", WindowOpts{Flags: sdl.WINDOW_FULLSCREEN, Width: 0, Height: 0}, false},
+		{"fullscreen desktop zero", WindowOpts{Flags: sdl.WINDOW_FULLSCREEN_DESKTOP}, true},
+		{"fullscreen desktop sized", WindowOpts{Flags: sdl.WINDOW_FULLSCREEN_DESKTOP, Width: 800, Height: 600}, true},
+		{"fullscreen desktop negative", WindowOpts{Flags: sdl.WINDOW_FULLSCREEN_DESKTOP, Width: -1}, false},
+		{"fullscreen desktop bad opacity", WindowOpts{Flags: sdl.WINDOW_FULLSCREEN_DESKTOP, Opacity: 2}, false},
+		{"opacity", WindowOpts{Width: 800, Height: 600, Opacity: 0.5}, true},
+		{"negative opacity", WindowOpts{Width: 800, Height: 600, Opacity: -0.5}, false},
+		{"NaN opacity", WindowOpts{Width: 800, Height: 600, Opacity: float32(math.NaN())}, false},
+	}
+	for _, test := range tests {
+		err := test.opts.Validate()
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("%s: Validate returned %v, want valid=%t", test.name, err, test.valid)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package nksdl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SharedFontAtlas is a baked font atlas, with the texture it was uploaded to,
+// which several Drivers can use instead of each baking the same fonts, e.g. in
+// an application with a Driver per viewport of one window. The atlas is
+// reference counted: its creator holds one reference, which it gives up with
+// Release, and every Driver using it holds another from Init until Destroy. The
+// atlas and its texture are freed when the last reference is given up.
+//
+// SDL textures belong to the renderer that created them, so a shared atlas can
+// only be used by Drivers whose SDLDriver returns that same renderer from
+// CreateRenderer; for a Driver with another renderer, the atlas must be baked
+// and uploaded again. Like the rest of SDL, a SharedFontAtlas must only be used
+// on the main thread, and its last reference must be given up before its
+// renderer is destroyed. Since the renderer outlives the Drivers, they leave it
+// to the application to destroy (see Driver.Destroy).
+type SharedFontAtlas struct {
+	atlas    *nk.FontAtlas
+	font     *nk.Font
+	renderer *sdl.Renderer
+	tex      *sdl.Texture
+	texW     int32
+	texH     int32
+	null     nk.DrawNullTexture
+	refs     int
+}
+
+// NewSharedFontAtlas shares atlas, which the caller has created, baked,
+// uploaded to tex with renderer, and ended with the handle of tex (see
+// TextureHandle), which returned null. The Drivers using the atlas draw all
+// text with font, which must belong to atlas, at every render scale; fonts
+// registered with AddFont are not available with a shared atlas. Ownership of
+// atlas and tex passes to the returned SharedFontAtlas, of which the caller
+// holds one reference.
+func NewSharedFontAtlas(renderer *sdl.Renderer, atlas *nk.FontAtlas, font *nk.Font, tex *sdl.Texture, null nk.DrawNullTexture) (*SharedFontAtlas, error) {
+	if renderer == nil || atlas == nil || font == nil || tex == nil {
+		return nil, errors.New("renderer, atlas, font, and texture must not be nil")
+	}
+	_, _, width, height, err := tex.Query()
+	if err != nil {
+		return nil, fmt.Errorf("querying font texture: %w", err)
+	}
+	return &SharedFontAtlas{
+		atlas:    atlas,
+		font:     font,
+		renderer: renderer,
+		tex:      tex,
+		texW:     width,
+		texH:     height,
+		null:     null,
+		refs:     1,
+	}, nil
+}
+
+// Release gives up the caller's reference to s, freeing the atlas and its
+// texture if no Driver is using them. Release must be called at most once.
+func (s *SharedFontAtlas) Release() error {
+	return s.release()
+}
+
+// retain adds a reference to s.
+func (s *SharedFontAtlas) retain() error {
+	if s.refs <= 0 {
+		return errors.New("shared font atlas has already been freed")
+	}
+	s.refs++
+	return nil
+}
+
+// release gives up a reference to s, freeing it if it was the last.
+func (s *SharedFontAtlas) release() error {
+	if s.refs <= 0 {
+		return errors.New("shared font atlas has already been freed")
+	}
+	s.refs--
+	if s.refs > 0 {
+		return nil
+	}
+	s.atlas.Free()
+	if err := s.tex.Destroy(); err != nil {
+		return fmt.Errorf("destroying shared font texture: %w", err)
+	}
+	return nil
+}
+
+// SetSharedFontAtlas makes d use the shared font atlas s instead of baking its
+// own fonts with its NkDriver. Init fails if s was uploaded with a renderer
+// other than d's, if fonts were registered with AddFont, or if async font
+// loading is enabled (see SetAsyncFontLoading). SetSharedFontAtlas must be
+// called before Init; a nil s restores baking fonts with the NkDriver. With a
+// shared atlas set, d does not own its window and renderer: Destroy does not
+// destroy them or quit SDL, as the other Drivers sharing them may outlive d.
+func (d *Driver) SetSharedFontAtlas(s *SharedFontAtlas) error {
+	if d.context != nil {
+		return errors.New("shared font atlas cannot be set after Init")
+	}
+	d.shared = s
+	return nil
+}
+
+// useSharedFonts puts the shared font atlas in use, taking a reference to it.
+func (d *Driver) useSharedFonts() error {
+	s := d.shared
+	if s.renderer != d.renderer {
+		return errors.New("shared font atlas was uploaded with a different renderer; " +
+			"textures cannot be shared between renderers, so bake the atlas again for this renderer")
+	} else if len(d.namedFonts) != 0 {
+		return errors.New("fonts registered with AddFont cannot be used with a shared font atlas")
+	} else if d.asyncFonts {
+		return errors.New("async font loading cannot be used with a shared font atlas")
+	}
+	if err := s.retain(); err != nil {
+		return err
+	}
+	d.font, d.largeFont = s.font, s.font
+	d.fontTex, d.fontTexW, d.fontTexH = s.tex, s.texW, s.texH
+	d.atlasNull = s.null
+	if d.nullTex == nil {
+		d.null = s.null
+	}
+	d.rebuildConvertConfig()
+	d.setDefaultFont()
+	return nil
+}
@@ -0,0 +1,125 @@
+package nksdl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// sharedSDLDriver is an SDLDriver which gives every Driver the same window and
+// renderer, as in an application with a Driver per viewport of one window.
+type sharedSDLDriver struct {
+	window   *sdl.Window
+	renderer *sdl.Renderer
+}
+
+func (s *sharedSDLDriver) InitSDL() error {
+	return nil
+}
+
+func (s *sharedSDLDriver) CreateWindow() (*sdl.Window, error) {
+	return s.window, nil
+}
+
+func (s *sharedSDLDriver) CreateRenderer(window *sdl.Window) (*sdl.Renderer, error) {
+	return s.renderer, nil
+}
+
+// newSharedSDLDriver initializes SDL and creates a hidden window with a
+// software renderer, which are destroyed, and SDL quit, when t ends.
+func newSharedSDLDriver(t *testing.T) *sharedSDLDriver {
+	t.Helper()
+	if err := sdl.Init(sdl.INIT_VIDEO); err != nil {
+		t.Fatalf("initializing SDL: %v", err)
+	}
+	t.Cleanup(sdl.Quit)
+	window, err := sdl.CreateWindow("nksdl test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
+		200, 100, sdl.WINDOW_HIDDEN)
+	if err != nil {
+		t.Fatalf("creating window: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := window.Destroy(); err != nil {
+			t.Errorf("destroying window: %v", err)
+		}
+	})
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_SOFTWARE)
+	if err != nil {
+		t.Fatalf("creating renderer: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := renderer.Destroy(); err != nil {
+			t.Errorf("destroying renderer: %v", err)
+		}
+	})
+	return &sharedSDLDriver{window: window, renderer: renderer}
+}
+
+// newSharedFontAtlas bakes the default font of nkDriver and uploads it with
+// renderer, as an application sharing an atlas would.
+func newSharedFontAtlas(t *testing.T, nkDriver NkDriver, renderer *sdl.Renderer) *SharedFontAtlas {
+	t.Helper()
+	b, err := buildFonts(nkDriver, nil)
+	if err != nil {
+		t.Fatalf("building fonts: %v", err)
+	}
+	tex, err := renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STATIC, b.width, b.height)
+	if err != nil {
+		b.atlas.Free()
+		t.Fatalf("creating font texture: %v", err)
+	}
+	if err := tex.Update(nil, b.image, int(4*b.width)); err != nil {
+		tex.Destroy()
+		b.atlas.Free()
+		t.Fatalf("uploading font atlas: %v", err)
+	}
+	null := centerNullTexture(b.atlas.End(TextureHandle(tex)), b.width, b.height)
+	shared, err := NewSharedFontAtlas(renderer, b.atlas, b.font, tex, null)
+	if err != nil {
+		tex.Destroy()
+		b.atlas.Free()
+		t.Fatalf("sharing font atlas: %v", err)
+	}
+	return shared
+}
+
+func TestSharedFontAtlasOutlivesDriver(t *testing.T) {
+	if err := checkSDLVersion(); err != nil {
+		t.Skip(err)
+	}
+	sdlDriver := newSharedSDLDriver(t)
+	nkDriver := &DefaultNkDriver{Font: FontOpts{Size: 13}}
+	shared := newSharedFontAtlas(t, nkDriver, sdlDriver.renderer)
+	drivers := make([]*TestDriver, 2)
+	for i := range drivers {
+		td := &TestDriver{
+			Driver: NewDriver(sdlDriver, nkDriver, DefaultBindings, nil),
+			clock:  testEpoch,
+		}
+		td.setClock(func() time.Time { return td.clock })
+		if err := td.SetSharedFontAtlas(shared); err != nil {
+			t.Fatal(err)
+		}
+		if err := td.Init(); err != nil {
+			t.Fatalf("initializing Driver %d: %v", i, err)
+		}
+		drivers[i] = td
+	}
+	if err := shared.Release(); err != nil {
+		t.Fatalf("releasing the creator's reference: %v", err)
+	}
+	if err := drivers[0].Destroy(); err != nil {
+		t.Fatalf("destroying the first Driver: %v", err)
+	}
+	// the renderer and the atlas must still be usable by the other Driver
+	if err := drivers[1].Frame(buildButton); err != nil {
+		t.Errorf("frame after destroying the first Driver: %v", err)
+	}
+	if err := drivers[1].Destroy(); err != nil {
+		t.Errorf("destroying the second Driver: %v", err)
+	}
+	if shared.refs != 0 {
+		t.Errorf("shared atlas has %d references after all were given up, want 0", shared.refs)
+	}
+}
@@ -0,0 +1,189 @@
+package nksdl
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// triangle is a filled triangle of a software cursor shape.
+type triangle [3]sdl.FPoint
+
+// softwareCursorShapes are the shapes of the software cursor for each style,
+// in Nuklear units relative to the hot spot.
+var softwareCursorShapes = [cursorStyleCount][]triangle{
+	CursorArrow: {
+		{{X: 0, Y: 0}, {X: 0, Y: 15}, {X: 11, Y: 11}},
+		{{X: 3, Y: 11}, {X: 6, Y: 10}, {X: 9, Y: 17}},
+		{{X: 6, Y: 10}, {X: 9, Y: 17}, {X: 11, Y: 16}},
+	},
+	CursorText: concatShapes(
+		rectShape(-3, -8, 6, 1.5),
+		rectShape(-0.75, -8, 1.5, 16),
+		rectShape(-3, 6.5, 6, 1.5),
+	),
+	CursorMove: concatShapes(
+		doubleArrowShape(7),
+		rotateShape(doubleArrowShape(7), math.Pi/2),
+	),
+	CursorResizeVertical:         rotateShape(doubleArrowShape(8), math.Pi/2),
+	CursorResizeHorizontal:       doubleArrowShape(8),
+	CursorResizeTopLeftDownRight: rotateShape(doubleArrowShape(8), math.Pi/4),
+	CursorResizeTopRightDownLeft: rotateShape(doubleArrowShape(8), -math.Pi/4),
+}
+
+// softwareCursorOutline are the offsets at which the outline of the software
+// cursor is drawn.
+var softwareCursorOutline = []sdl.FPoint{{X: -1}, {X: 1}, {Y: -1}, {Y: 1}}
+
+func rectShape(x, y, w, h float32) []triangle {
+	return []triangle{
+		{{X: x, Y: y}, {X: x + w, Y: y}, {X: x, Y: y + h}},
+		{{X: x + w, Y: y}, {X: x + w, Y: y + h}, {X: x, Y: y + h}},
+	}
+}
+
+// doubleArrowShape is a horizontal arrow centered on the origin, with heads at
+// both ends reaching halfLength from the origin.
+func doubleArrowShape(halfLength float32) []triangle {
+	return concatShapes(
+		rectShape(-halfLength+3, -1, 2*halfLength-6, 2),
+		[]triangle{
+			{{X: -halfLength, Y: 0}, {X: -halfLength + 4, Y: -4}, {X: -halfLength + 4, Y: 4}},
+			{{X: halfLength, Y: 0}, {X: halfLength - 4, Y: -4}, {X: halfLength - 4, Y: 4}},
+		},
+	)
+}
+
+func rotateShape(shape []triangle, angle float64) []triangle {
+	sin, cos := float32(math.Sin(angle)), float32(math.Cos(angle))
+	rotated := make([]triangle, len(shape))
+	for i, t := range shape {
+		for j, p := range t {
+			rotated[i][j] = sdl.FPoint{X: p.X*cos - p.Y*sin, Y: p.X*sin + p.Y*cos}
+		}
+	}
+	return rotated
+}
+
+func concatShapes(shapes ...[]triangle) []triangle {
+	var all []triangle
+	for _, shape := range shapes {
+		all = append(all, shape...)
+	}
+	return all
+}
+
+func (d *Driver) SoftwareCursor() bool {
+	return d.cursors.software
+}
+
+// SetSoftwareCursor sets whether the mouse cursor is drawn by the renderer, on
+// top of the GUI, instead of by the system. This guarantees a consistent cursor
+// on platforms or backends where system cursors do not render correctly, e.g.
+// in some fullscreen modes. The software cursor follows the cursor style (see
+// SetCursorStyle), but is always drawn with built-in shapes, even if cursors
+// were loaded by LoadCursorAtlas. While it is enabled, the system cursor is
+// hidden.
+func (d *Driver) SetSoftwareCursor(software bool) error {
+	toggle := sdl.ENABLE
+	if software {
+		toggle = sdl.DISABLE
+	}
+	if _, err := sdl.ShowCursor(toggle); err != nil {
+		return fmt.Errorf("showing or hiding system cursor: %w", err)
+	}
+	d.cursors.software = software
+	return nil
+}
+
+// drawSoftwareCursor draws the software cursor, if it is enabled and the mouse
+// is in the window.
+func (d *Driver) drawSoftwareCursor() error {
+	if !d.cursors.software || !d.cursors.inside {
+		return nil
+	}
+	vertices := appendCursorVertices(nil, d.cursors.style, d.cursors.x, d.cursors.y,
+		sdl.Color{R: 255, G: 255, B: 255, A: 255})
+	if err := d.renderer.RenderGeometry(nil, vertices, nil); err != nil {
+		return fmt.Errorf("rendering software cursor: %w", err)
+	}
+	return nil
+}
+
+// appendCursorVertices appends the vertices of the software cursor shape of
+// the given style, outlined in black and filled with fill, with its hot spot
+// at (x, y), to vertices.
+func appendCursorVertices(vertices []sdl.Vertex, style CursorStyle, x, y int32, fill sdl.Color) []sdl.Vertex {
+	shape := softwareCursorShapes[style]
+	appendShape := func(offset sdl.FPoint, color sdl.Color) {
+		for _, t := range shape {
+			for _, p := range t {
+				vertices = append(vertices, sdl.Vertex{
+					Position: sdl.FPoint{
+						X: float32(x) + offset.X + p.X,
+						Y: float32(y) + offset.Y + p.Y,
+					},
+					Color: color,
+				})
+			}
+		}
+	}
+	for _, offset := range softwareCursorOutline {
+		appendShape(offset, sdl.Color{A: 255})
+	}
+	appendShape(sdl.FPoint{}, fill)
+	return vertices
+}
+
+// RemoteCursor is a cursor drawn on top of the GUI for another user, e.g. a
+// participant in a collaborative session (see SetRemoteCursors).
+type RemoteCursor struct {
+	// X and Y give the position of the cursor's hot spot, in the coordinates
+	// used by Nuklear.
+	X, Y int32
+	// Style is the shape of the cursor.
+	Style CursorStyle
+	// Color is the fill color of the cursor, which is outlined in black.
+	Color sdl.Color
+}
+
+// SetRemoteCursors sets the cursors of other users to draw on top of the GUI,
+// with the same built-in shapes as the software cursor (see
+// SetSoftwareCursor). Remote cursors are only drawn; they do not move the
+// local cursor or provide input to Nuklear. The local software cursor, if
+// enabled, is drawn above them. The application should call SetRemoteCursors
+// whenever the remote cursors move; they are drawn by every FrameEnd until
+// then. The cursors are copied, and a nil or empty slice removes them all.
+func (d *Driver) SetRemoteCursors(cursors []RemoteCursor) error {
+	for i, cursor := range cursors {
+		if cursor.Style < 0 || cursor.Style >= cursorStyleCount {
+			return fmt.Errorf("remote cursor %d has invalid style %d", i, cursor.Style)
+		}
+	}
+	d.cursors.remote = append(d.cursors.remote[:0], cursors...)
+	return nil
+}
+
+// drawRemoteCursors draws the remote cursors, if any.
+func (d *Driver) drawRemoteCursors() error {
+	if len(d.cursors.remote) == 0 {
+		return nil
+	}
+	var vertices []sdl.Vertex
+	originX, originY := d.eventHandler.originX, d.eventHandler.originY
+	orient := &d.eventHandler.orient
+	for _, cursor := range d.cursors.remote {
+		x, y := cursor.X, cursor.Y
+		if !orient.identity() {
+			fx, fy := orient.toScreen(float32(x), float32(y))
+			x, y = int32(fx), int32(fy)
+		}
+		vertices = appendCursorVertices(vertices, cursor.Style, x+originX, y+originY, cursor.Color)
+	}
+	if err := d.renderer.RenderGeometry(nil, vertices, nil); err != nil {
+		return fmt.Errorf("rendering remote cursors: %w", err)
+	}
+	return nil
+}
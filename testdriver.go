@@ -0,0 +1,265 @@
+package nksdl
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// testFrameInterval is the time by which the clock of a TestDriver advances
+// with every frame.
+const testFrameInterval = time.Second / 60
+
+// testEpoch is the starting time of the clock of a TestDriver.
+var testEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// TestDriver wraps a Driver for driving a GUI from tests. Input is injected
+// into the SDL event queue by methods such as Click and Type, and is reported
+// to Nuklear by the next call to Frame, after which the rendered frame can be
+// inspected with PixelAt. Nuklear only sees a button or key as pressed if it is
+// still down at the end of a frame, so Click and PressKey inject the release
+// after the next frame, to be reported in the frame after it. The clock seen
+// by the Driver starts at a fixed time and advances by exactly 1/60 s per
+// frame, so tests are deterministic.
+//
+// TestDriver uses the software renderer and a hidden window. To run without a
+// display, e.g. in CI, set the environment variable SDL_VIDEODRIVER=dummy. As
+// with Driver, all calls must be made from the main OS thread. For example:
+//
+//	td, err := nksdl.NewTestDriver(&nksdl.DefaultNkDriver{Font: nksdl.FontOpts{Size: 13}}, 200, 100)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer td.Destroy()
+//	clicked := false
+//	build := func(nkc *nk.Context) {
+//		if nkc.Begin("test", &nk.Rect{W: 200, H: 100}, nk.WindowNoScrollbar) {
+//			nkc.LayoutRowStatic(30, 80, 1)
+//			if nkc.ButtonText("OK") {
+//				clicked = true
+//			}
+//		}
+//		nkc.End()
+//	}
+//	if err := td.Frame(build); err != nil {
+//		t.Fatal(err)
+//	}
+//	if err := td.Click(40, 20); err != nil {
+//		t.Fatal(err)
+//	}
+//	if err := td.Frame(build); err != nil {
+//		t.Fatal(err)
+//	}
+//	if !clicked {
+//		t.Error("button was not clicked")
+//	}
+type TestDriver struct {
+	*Driver
+	clock  time.Time
+	pixels []byte // RGBA32 pixels of the last frame
+	width  int32  // width of the last frame in pixels
+	height int32  // height of the last frame in pixels
+
+	later []sdl.Event // events injected after the next frame
+}
+
+// NewTestDriver creates and initializes a TestDriver with a window of the
+// given size. The TestDriver must be destroyed with Destroy.
+func NewTestDriver(nkDriver NkDriver, width, height int32) (*TestDriver, error) {
+	sdlDriver := &DefaultSDLDriver{
+		InitFlags: sdl.INIT_VIDEO,
+		Window: WindowOpts{
+			Title:  "nksdl test",
+			PosX:   sdl.WINDOWPOS_UNDEFINED,
+			PosY:   sdl.WINDOWPOS_UNDEFINED,
+			Width:  width,
+			Height: height,
+			Flags:  sdl.WINDOW_HIDDEN,
+		},
+		Render: RenderOpts{
+			Drivers: []string{"software"},
+		},
+	}
+	td := &TestDriver{
+		Driver: NewDriver(sdlDriver, nkDriver, DefaultBindings, nil),
+		clock:  testEpoch,
+	}
+	td.setClock(func() time.Time { return td.clock })
+	if err := td.Init(); err != nil {
+		return nil, err
+	}
+	return td, nil
+}
+
+// Now returns the current time of the clock seen by the Driver.
+func (td *TestDriver) Now() time.Time {
+	return td.clock
+}
+
+// Advance advances the clock seen by the Driver by dt, in addition to the
+// advance made by every frame.
+func (td *TestDriver) Advance(dt time.Duration) {
+	td.clock = td.clock.Add(dt)
+}
+
+// Frame runs a whole frame, calling build between FrameStart and FrameEnd to
+// declare the GUI, and captures the rendered frame for PixelAt. Any input
+// injected since the last frame is reported to Nuklear in this frame. Like
+// FrameEnd, Frame runs the idle work set by SetIdleWork before presenting.
+func (td *TestDriver) Frame(build func(nkc *nk.Context)) error {
+	td.Advance(testFrameInterval)
+	if err := td.FrameStart(); err != nil {
+		return err
+	}
+	build(td.context)
+	if err := td.finishFrame(); err != nil {
+		return err
+	}
+	if err := td.capture(); err != nil {
+		return fmt.Errorf("capturing frame: %w", err)
+	}
+	td.runIdleWork()
+	td.present()
+	pending := td.later
+	td.later = nil
+	for _, event := range pending {
+		if err := td.push(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PixelAt returns the color of the pixel at (x, y), in renderer output
+// coordinates, in the frame captured by the last call to Frame. It returns
+// transparent black if the pixel is out of bounds or no frame was captured.
+func (td *TestDriver) PixelAt(x, y int32) sdl.Color {
+	if x < 0 || x >= td.width || y < 0 || y >= td.height {
+		return sdl.Color{}
+	}
+	i := 4 * (int(y)*int(td.width) + int(x))
+	return sdl.Color{R: td.pixels[i], G: td.pixels[i+1], B: td.pixels[i+2], A: td.pixels[i+3]}
+}
+
+// MoveMouse injects motion of the mouse cursor to (x, y), in the coordinates
+// used by Nuklear.
+func (td *TestDriver) MoveMouse(x, y int32) error {
+	windowID, err := td.window.GetID()
+	if err != nil {
+		return fmt.Errorf("getting window ID: %w", err)
+	}
+	return td.push(&sdl.MouseMotionEvent{
+		Type:     sdl.MOUSEMOTION,
+		WindowID: windowID,
+		X:        x,
+		Y:        y,
+	})
+}
+
+// Click injects a left click at (x, y), in the coordinates used by Nuklear,
+// including the motion of the mouse cursor to that point. The button is
+// pressed in the next frame and released in the frame after it, so a button
+// widget at (x, y) reports being clicked in the next frame.
+func (td *TestDriver) Click(x, y int32) error {
+	if err := td.MoveMouse(x, y); err != nil {
+		return err
+	}
+	windowID, err := td.window.GetID()
+	if err != nil {
+		return fmt.Errorf("getting window ID: %w", err)
+	}
+	press := &sdl.MouseButtonEvent{
+		Type:     sdl.MOUSEBUTTONDOWN,
+		WindowID: windowID,
+		Button:   sdl.BUTTON_LEFT,
+		State:    sdl.PRESSED,
+		Clicks:   1,
+		X:        x,
+		Y:        y,
+	}
+	release := *press
+	release.Type, release.State = sdl.MOUSEBUTTONUP, sdl.RELEASED
+	td.later = append(td.later, &release)
+	return td.push(press)
+}
+
+// Type injects text input, as if text had been typed on the keyboard.
+func (td *TestDriver) Type(text string) error {
+	windowID, err := td.window.GetID()
+	if err != nil {
+		return fmt.Errorf("getting window ID: %w", err)
+	}
+	for len(text) != 0 {
+		event := &sdl.TextInputEvent{
+			Type:     sdl.TEXTINPUT,
+			WindowID: windowID,
+		}
+		// split text at rune boundaries into null-terminated chunks
+		n := 0
+		for n < len(text) {
+			_, size := utf8.DecodeRuneInString(text[n:])
+			if n+size >= len(event.Text) {
+				break
+			}
+			n += size
+		}
+		copy(event.Text[:], text[:n])
+		if err := td.push(event); err != nil {
+			return err
+		}
+		text = text[n:]
+	}
+	return nil
+}
+
+// PressKey injects a press and release of the key with the given keycode and
+// modifiers, which are mapped to Nuklear actions by the bindings in use. Like
+// the button of Click, the key is pressed in the next frame and released in
+// the frame after it.
+func (td *TestDriver) PressKey(code sdl.Keycode, mod sdl.Keymod) error {
+	windowID, err := td.window.GetID()
+	if err != nil {
+		return fmt.Errorf("getting window ID: %w", err)
+	}
+	press := &sdl.KeyboardEvent{
+		Type:     sdl.KEYDOWN,
+		WindowID: windowID,
+		State:    sdl.PRESSED,
+		Keysym: sdl.Keysym{
+			Scancode: sdl.GetScancodeFromKey(code),
+			Sym:      code,
+			Mod:      uint16(mod),
+		},
+	}
+	release := *press
+	release.Type, release.State = sdl.KEYUP, sdl.RELEASED
+	td.later = append(td.later, &release)
+	return td.push(press)
+}
+
+func (td *TestDriver) push(event sdl.Event) error {
+	if _, err := sdl.PushEvent(event); err != nil {
+		return fmt.Errorf("pushing event %#v: %w", event, err)
+	}
+	return nil
+}
+
+// capture reads the pixels of the frame rendered so far.
+func (td *TestDriver) capture() error {
+	width, height, err := td.renderer.GetOutputSize()
+	if err != nil {
+		return fmt.Errorf("getting renderer output size: %w", err)
+	}
+	pixels := make([]byte, 4*int(width)*int(height))
+	if len(pixels) != 0 {
+		if err := td.renderer.ReadPixels(nil, uint32(sdl.PIXELFORMAT_RGBA32), unsafe.Pointer(&pixels[0]), int(4*width)); err != nil {
+			return fmt.Errorf("reading pixels: %w", err)
+		}
+	}
+	td.pixels, td.width, td.height = pixels, width, height
+	return nil
+}
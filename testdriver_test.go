@@ -0,0 +1,122 @@
+package nksdl
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/kbolino/go-nk"
+)
+
+func init() {
+	// SDL must be called from the main OS thread
+	runtime.LockOSThread()
+}
+
+func TestMain(m *testing.M) {
+	if os.Getenv("SDL_VIDEODRIVER") == "" {
+		os.Setenv("SDL_VIDEODRIVER", "dummy")
+	}
+	os.Exit(m.Run())
+}
+
+// newTestDriver creates a TestDriver for t, which is destroyed when t ends.
+func newTestDriver(t *testing.T) *TestDriver {
+	t.Helper()
+	if err := checkSDLVersion(); err != nil {
+		t.Skip(err)
+	}
+	td, err := NewTestDriver(&DefaultNkDriver{Font: FontOpts{Size: 13}}, 200, 100)
+	if err != nil {
+		t.Fatalf("creating TestDriver: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := td.Destroy(); err != nil {
+			t.Errorf("destroying TestDriver: %v", err)
+		}
+	})
+	return td
+}
+
+func TestTestDriverClick(t *testing.T) {
+	td := newTestDriver(t)
+	clicks := 0
+	build := func(nkc *nk.Context) {
+		if nkc.Begin("test", &nk.Rect{W: 200, H: 100}, nk.WindowNoScrollbar) {
+			nkc.LayoutRowStatic(30, 80, 1)
+			if nkc.ButtonText("OK") {
+				clicks++
+			}
+		}
+		nkc.End()
+	}
+	frames := []struct {
+		name   string
+		click  bool // whether to click the button before the frame
+		clicks int  // expected clicks after the frame
+	}{
+		{"first frame", false, 0},
+		{"press", true, 1},
+		{"release", false, 1},
+		{"idle", false, 1},
+		{"press again", true, 2},
+		{"release again", false, 2},
+	}
+	for _, frame := range frames {
+		if frame.click {
+			if err := td.Click(40, 20); err != nil {
+				t.Fatalf("%s: %v", frame.name, err)
+			}
+		}
+		if err := td.Frame(build); err != nil {
+			t.Fatalf("%s: %v", frame.name, err)
+		}
+		if clicks != frame.clicks {
+			t.Errorf("%s: got %d clicks, want %d", frame.name, clicks, frame.clicks)
+		}
+	}
+}
+
+func TestTestDriverClickOutside(t *testing.T) {
+	td := newTestDriver(t)
+	clicked := false
+	build := func(nkc *nk.Context) {
+		if nkc.Begin("test", &nk.Rect{W: 200, H: 100}, nk.WindowNoScrollbar) {
+			nkc.LayoutRowStatic(30, 80, 1)
+			if nkc.ButtonText("OK") {
+				clicked = true
+			}
+		}
+		nkc.End()
+	}
+	for i := 0; i < 3; i++ {
+		if i == 1 {
+			if err := td.Click(150, 80); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := td.Frame(build); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if clicked {
+		t.Error("button was clicked by a click outside of it")
+	}
+}
+
+func TestTestDriverIdleWork(t *testing.T) {
+	td := newTestDriver(t)
+	calls := 0
+	td.SetIdleWork(func(budget time.Duration) {
+		calls++
+	})
+	for i := 0; i < 2; i++ {
+		if err := td.Frame(func(nkc *nk.Context) {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("idle work ran %d times in 2 frames, want 2", calls)
+	}
+}
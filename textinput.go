@@ -0,0 +1,144 @@
+package nksdl
+
+import (
+	"fmt"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// textInputState tracks which edit widgets want SDL text input.
+type textInputState struct {
+	managed bool     // whether Driver starts and stops text input
+	wanted  bool     // whether an active edit was tracked this frame
+	editing bool     // whether an active edit was tracked last frame
+	rect    sdl.Rect // bounds of the active edit
+
+	direction TextDirection
+	rectFunc  func(bounds sdl.Rect) sdl.Rect // see SetTextInputRectFunc
+}
+
+// TextDirection is the direction in which text is written.
+type TextDirection int32
+
+const (
+	// TextDirectionLTR is left-to-right text, as in Latin scripts.
+	TextDirectionLTR TextDirection = iota
+	// TextDirectionRTL is right-to-left text, as in Arabic and Hebrew.
+	TextDirectionRTL
+)
+
+func (d *Driver) TextInputDirection() TextDirection {
+	return d.textInput.direction
+}
+
+// SetTextInputDirection sets the direction of the text being input, which
+// determines where the IME is placed relative to the active edit (see
+// TrackEdit): at its left edge for left-to-right text, the default, or at its
+// right edge for right-to-left text, where the caret of an empty edit would
+// be in a right-to-left GUI.
+//
+// This is the extent of right-to-left support: Nuklear lays out all text from
+// left to right, without bidirectional reordering or the shaping that Arabic
+// script needs, so right-to-left text is shown in logical order from left to
+// right, and the caret and the arrow keys move through it in logical order.
+// For the IME to follow the caret exactly, see SetTextInputRectFunc.
+func (d *Driver) SetTextInputDirection(direction TextDirection) error {
+	if direction != TextDirectionLTR && direction != TextDirectionRTL {
+		return fmt.Errorf("text direction %d is invalid", direction)
+	}
+	d.textInput.direction = direction
+	return nil
+}
+
+// SetTextInputRectFunc sets a function which computes the rectangle given to
+// SDL_SetTextInputRect, near which the IME shows its candidate list, from the
+// bounds of the active edit (see TrackEdit), both in the coordinates Nuklear
+// uses. This lets an application that knows more about its text, e.g. where
+// the caret is in a right-to-left layout of its own, place the IME precisely.
+// The function takes precedence over the text input direction (see
+// SetTextInputDirection). A nil function restores the default placement.
+func (d *Driver) SetTextInputRectFunc(rectFunc func(bounds sdl.Rect) sdl.Rect) {
+	d.textInput.rectFunc = rectFunc
+}
+
+func (d *Driver) TextInputManaged() bool {
+	return d.textInput.managed
+}
+
+// SetTextInputManaged sets whether d starts and stops SDL text input itself.
+// If it does, then text input is active only while an edit widget reported to
+// TrackEdit is active. On platforms with a software keyboard (mobile, Steam
+// Deck, consoles), starting text input shows the on-screen keyboard, and
+// stopping it hides the keyboard again.
+func (d *Driver) SetTextInputManaged(managed bool) {
+	d.textInput.managed = managed
+}
+
+// TrackEdit reports the events returned by an edit widget, e.g. from
+// nk.Context.EditString, along with the bounds of the widget in the same
+// coordinates Nuklear uses. TrackEdit should be called for every edit widget
+// in every frame. If text input is managed (see SetTextInputManaged), it will
+// be active from the end of any frame in which a tracked edit was active, and
+// the bounds of that edit will be given to SDL so that IME candidate lists and
+// the on-screen keyboard do not cover it.
+func (d *Driver) TrackEdit(events nk.EditEvents, bounds nk.Rect) {
+	if events&nk.EditActive == 0 {
+		return
+	}
+	d.textInput.wanted = true
+	d.textInput.rect = sdl.Rect{
+		X: int32(bounds.X),
+		Y: int32(bounds.Y),
+		W: int32(bounds.W),
+		H: int32(bounds.H),
+	}
+}
+
+// TextInputActive returns true if SDL text input is active.
+func (d *Driver) TextInputActive() bool {
+	return sdl.IsTextInputActive()
+}
+
+// HasScreenKeyboardSupport returns true if the platform has an on-screen
+// keyboard.
+func (d *Driver) HasScreenKeyboardSupport() bool {
+	return sdl.HasScreenKeyboardSupport()
+}
+
+// ScreenKeyboardShown returns true if the on-screen keyboard is shown for the
+// window.
+func (d *Driver) ScreenKeyboardShown() bool {
+	return sdl.IsScreenKeyboardShown(d.window)
+}
+
+// updateTextInput starts or stops text input according to the edits tracked
+// during the frame, and then resets the tracking for the next frame.
+func (d *Driver) updateTextInput() {
+	d.textInput.editing = d.textInput.wanted
+	if !d.textInput.managed {
+		d.textInput.wanted = false
+		return
+	}
+	if d.textInput.wanted {
+		rect := d.textInput.rect
+		if d.textInput.rectFunc != nil {
+			rect = d.textInput.rectFunc(rect)
+		} else if d.textInput.direction == TextDirectionRTL && rect.W > 1 {
+			rect.X += rect.W - 1
+			rect.W = 1
+		}
+		if orient := &d.eventHandler.orient; !orient.identity() {
+			rect = orient.rectToScreen(rect)
+		}
+		rect.X += d.guiViewport.X
+		rect.Y += d.guiViewport.Y
+		sdl.SetTextInputRect(&rect)
+		if !sdl.IsTextInputActive() {
+			sdl.StartTextInput()
+		}
+	} else if sdl.IsTextInputActive() {
+		sdl.StopTextInput()
+	}
+	d.textInput.wanted = false
+}
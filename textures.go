@@ -0,0 +1,110 @@
+package nksdl
+
+import (
+	"fmt"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// TextureResolver resolves the texture handle of a Nuklear draw command, e.g.
+// the handle of an nk.Image, to the texture to render it with. This allows an
+// application to store something other than an *sdl.Texture pointer in the
+// handles of its images, such as an ID into its own texture cache.
+type TextureResolver func(handle nk.Handle) (*sdl.Texture, error)
+
+// TexturePointerResolver is a TextureResolver for handles which are
+// *sdl.Texture pointers. This is the behavior of Driver when no
+// TextureResolver is set.
+func TexturePointerResolver(handle nk.Handle) (*sdl.Texture, error) {
+	return HandleTexture(handle), nil
+}
+
+// SetTextureResolver sets the TextureResolver used to render draw commands.
+// Handles of textures created by d itself, i.e. the font atlas and images such
+// as those loaded by LoadImage, and of the texture set by SetNullTexture, are
+// always resolved by d, as is the zero handle, which means no texture. A nil resolver restores the default of
+// TexturePointerResolver.
+func (d *Driver) SetTextureResolver(resolver TextureResolver) {
+	d.textureResolver = resolver
+}
+
+// resolveTexture resolves handle to a texture.
+func (d *Driver) resolveTexture(handle nk.Handle) (*sdl.Texture, error) {
+	if handle == 0 || d.textureResolver == nil || d.ownsTexture(handle) {
+		return HandleTexture(handle), nil
+	}
+	texture, err := d.textureResolver(handle)
+	if err != nil {
+		return nil, fmt.Errorf("resolving texture handle %#x: %w", handle, err)
+	}
+	return texture, nil
+}
+
+// ownsTexture returns true if handle is the handle of a texture created by d,
+// or of the null texture set by SetNullTexture.
+func (d *Driver) ownsTexture(handle nk.Handle) bool {
+	texture := HandleTexture(handle)
+	if texture == d.fontTex || texture == d.nullTex {
+		return true
+	}
+	for _, image := range d.images {
+		if texture == image {
+			return true
+		}
+	}
+	return false
+}
+
+// pixelFormatNV12 and pixelFormatNV21 are SDL_PIXELFORMAT_NV12 and
+// SDL_PIXELFORMAT_NV21, the FourCC codes "NV12" and "NV21", which are not
+// defined by all versions of go-sdl2.
+const (
+	pixelFormatNV12 = 0x3231564e
+	pixelFormatNV21 = 0x3132564e
+)
+
+// yuvFormats are the pixel formats of YUV textures, which cannot be used as
+// the null texture.
+var yuvFormats = map[uint32]bool{
+	sdl.PIXELFORMAT_YV12: true,
+	sdl.PIXELFORMAT_IYUV: true,
+	sdl.PIXELFORMAT_YUY2: true,
+	sdl.PIXELFORMAT_UYVY: true,
+	sdl.PIXELFORMAT_YVYU: true,
+	pixelFormatNV12:      true,
+	pixelFormatNV21:      true,
+}
+
+// SetNullTexture sets the texture used to draw untextured geometry, e.g. the
+// backgrounds and borders of widgets, in place of the white pixel that Nuklear
+// reserves in the font atlas. An application which keeps a white texture for
+// its own rendering can use it for the GUI too, reducing texture switches when
+// GUI geometry is batched with its own. The pixel at (0, 0) of tex must be
+// opaque white, and tex must remain valid, and owned by the caller, for as
+// long as it is in use. SetNullTexture must be called after Init. A nil tex
+// restores the white pixel of the font atlas.
+func (d *Driver) SetNullTexture(tex *sdl.Texture) error {
+	if tex == nil {
+		d.nullTex = nil
+		d.null = d.atlasNull
+		d.rebuildConvertConfig()
+		return nil
+	}
+	format, _, width, height, err := tex.Query()
+	if err != nil {
+		return fmt.Errorf("querying null texture: %w", err)
+	} else if width < 1 || height < 1 {
+		return fmt.Errorf("null texture size %dx%d is empty", width, height)
+	} else if yuvFormats[format] {
+		return fmt.Errorf("null texture has YUV pixel format %s", sdl.GetPixelFormatName(uint(format)))
+	}
+	d.nullTex = tex
+	d.null = nk.DrawNullTexture{
+		Texture: TextureHandle(tex),
+		// center of the pixel at (0, 0)
+		UV: nk.Vec2{X: 0.5 / float32(width), Y: 0.5 / float32(height)},
+	}
+	d.rebuildConvertConfig()
+	return nil
+}
@@ -0,0 +1,77 @@
+package nksdl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kbolino/go-nk"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestTextureHandleNil(t *testing.T) {
+	if handle := TextureHandle(nil); handle != 0 {
+		t.Errorf("TextureHandle(nil) = %#x, want 0", handle)
+	}
+	if tex := HandleTexture(0); tex != nil {
+		t.Errorf("HandleTexture(0) = %p, want nil", tex)
+	}
+}
+
+// createTexture creates a small texture with the renderer of td, which is
+// destroyed when t ends.
+func createTexture(t *testing.T, td *TestDriver) *sdl.Texture {
+	t.Helper()
+	tex, err := td.Renderer().CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STATIC, 4, 4)
+	if err != nil {
+		t.Fatalf("creating texture: %v", err)
+	}
+	t.Cleanup(func() {
+		tex.Destroy()
+	})
+	return tex
+}
+
+func TestTextureHandleRoundTrip(t *testing.T) {
+	td := newTestDriver(t)
+	tex := createTexture(t, td)
+	if got := HandleTexture(TextureHandle(tex)); got != tex {
+		t.Errorf("HandleTexture(TextureHandle(%p)) = %p", tex, got)
+	}
+}
+
+func TestResolveTexture(t *testing.T) {
+	td := newTestDriver(t)
+	appTex := createTexture(t, td)
+	resolvedTex := createTexture(t, td)
+	resolveErr := errors.New("unknown handle")
+	var resolved []nk.Handle
+	td.SetTextureResolver(func(handle nk.Handle) (*sdl.Texture, error) {
+		resolved = append(resolved, handle)
+		if handle == TextureHandle(appTex) {
+			return resolvedTex, nil
+		}
+		return nil, resolveErr
+	})
+	tests := []struct {
+		name     string
+		handle   nk.Handle
+		want     *sdl.Texture
+		err      error
+		resolved bool // whether the resolver is called
+	}{
+		{"zero", 0, nil, nil, false},
+		{"font", TextureHandle(td.fontTex), td.fontTex, nil, false},
+		{"application", TextureHandle(appTex), resolvedTex, nil, true},
+		{"unknown", TextureHandle(resolvedTex), nil, resolveErr, true},
+	}
+	for _, test := range tests {
+		resolved = nil
+		got, err := td.resolveTexture(test.handle)
+		if got != test.want || !errors.Is(err, test.err) {
+			t.Errorf("%s: resolveTexture returned %p, %v, want %p, %v", test.name, got, err, test.want, test.err)
+		}
+		if called := len(resolved) != 0; called != test.resolved {
+			t.Errorf("%s: resolver called=%t, want %t", test.name, called, test.resolved)
+		}
+	}
+}
@@ -13,11 +13,18 @@ import (
 // https://github.com/golang/go/issues/51733 (Go 1.19)
 // https://github.com/golang/go/issues/51741 (Go 1.18.1)
 
-func textureToHandle(tex *sdl.Texture) nk.Handle {
+// TextureHandle converts tex to a handle, e.g. for an nk.Image, which Driver
+// converts back with HandleTexture when rendering. The handle is simply the
+// address of tex, which SDL allocates and never moves, so it is stable, but it
+// does not keep tex alive: tex must not be destroyed while Nuklear may still
+// render with the handle, i.e. until the frame after its last use has ended.
+func TextureHandle(tex *sdl.Texture) nk.Handle {
 	return nk.Handle(unsafe.Pointer(tex))
 }
 
-func handleToTexture(handle nk.Handle) *sdl.Texture {
+// HandleTexture converts a handle created by TextureHandle back to a texture.
+// Converting any other handle yields an invalid pointer.
+func HandleTexture(handle nk.Handle) *sdl.Texture {
 	return (*sdl.Texture)(unsafe.Pointer(handle))
 }
 
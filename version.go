@@ -0,0 +1,60 @@
+package nksdl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// VersionInfo describes the versions of the libraries in use, e.g. for bug
+// reports. Nuklear is compiled into go-nk, which does not report its version,
+// so the go-nk version in go.mod identifies it instead.
+type VersionInfo struct {
+	// SDLCompiled is the version of SDL that go-sdl2 was compiled against.
+	SDLCompiled sdl.Version
+	// SDLLinked is the version of SDL in use at runtime, which can differ from
+	// SDLCompiled when SDL is linked dynamically. Workarounds for SDL bugs,
+	// like the one for clip rects with the Metal renderer before SDL 2.0.22,
+	// depend on this version.
+	SDLLinked sdl.Version
+	// SDLRevision is the source revision of the SDL in use at runtime.
+	SDLRevision string
+	// Renderer is the name of the SDL render driver in use, e.g. "metal" or
+	// "opengl", which is empty before Init.
+	Renderer string
+}
+
+// String returns the version information on a single line, which is short
+// enough to show in a corner of the GUI, e.g. with nk.Context.Text.
+func (v VersionInfo) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "SDL %d.%d.%d", v.SDLLinked.Major, v.SDLLinked.Minor, v.SDLLinked.Patch)
+	if v.SDLCompiled != v.SDLLinked {
+		fmt.Fprintf(&b, " (compiled against %d.%d.%d)", v.SDLCompiled.Major, v.SDLCompiled.Minor, v.SDLCompiled.Patch)
+	}
+	if v.SDLRevision != "" {
+		fmt.Fprintf(&b, " %s", v.SDLRevision)
+	}
+	if v.Renderer != "" {
+		fmt.Fprintf(&b, ", renderer %s", v.Renderer)
+	}
+	return b.String()
+}
+
+// VersionInfo returns the versions of the libraries in use and the name of
+// the render driver.
+func (d *Driver) VersionInfo() (VersionInfo, error) {
+	var info VersionInfo
+	sdl.VERSION(&info.SDLCompiled)
+	sdl.GetVersion(&info.SDLLinked)
+	info.SDLRevision = sdl.GetRevision()
+	if d.renderer != nil {
+		rendererInfo, err := d.renderer.GetInfo()
+		if err != nil {
+			return info, fmt.Errorf("getting SDL renderer info: %w", err)
+		}
+		info.Renderer = rendererInfo.Name
+	}
+	return info, nil
+}
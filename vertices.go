@@ -0,0 +1,31 @@
+package nksdl
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// VertexFunc transforms the vertices of the GUI in place before they are
+// rendered, e.g. to tint or warp the whole GUI.
+type VertexFunc func(vertices []sdl.Vertex)
+
+// SetVertexFunc sets a function to be called with the vertices of every
+// Nuklear context of the Driver (see AddLayer) after they are converted from
+// draw commands and before they are passed to SDL_RenderGeometry, so that
+// simple effects need no custom backend. The vertices are those of the buffer
+// that RenderFrame renders from, laid out as sdl.Vertex, and have already been
+// oriented (see SetOrientation), so their positions are in the coordinates of
+// the area the GUI is drawn in. The function may change positions, colors,
+// and texture coordinates, but not the length of the slice, and must not
+// retain it. It runs on every frame for every vertex, so it must be fast. For
+// example, to tint the GUI red:
+//
+//	driver.SetVertexFunc(func(vertices []sdl.Vertex) {
+//		for i := range vertices {
+//			c := &vertices[i].Color
+//			c.G = uint8(uint16(c.G) * 3 / 4)
+//			c.B = uint8(uint16(c.B) * 3 / 4)
+//		}
+//	})
+//
+// A nil function disables this behavior.
+func (d *Driver) SetVertexFunc(fn VertexFunc) {
+	d.vertexFunc = fn
+}
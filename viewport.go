@@ -0,0 +1,70 @@
+package nksdl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// GUIViewport returns the sub-rectangle of the window to which the GUI is
+// confined, which is empty if the GUI covers the whole window.
+func (d *Driver) GUIViewport() sdl.Rect {
+	return d.guiViewport
+}
+
+// SetGUIViewport confines the GUI to a sub-rectangle of the window, e.g. to
+// show it as a docked panel beside a 3D view. The rectangle is in window
+// coordinates, which are the same as Nuklear coordinates before the render
+// scale is applied. Within it, Nuklear coordinates start at (0, 0): the GUI is
+// rendered offset by, and clipped to, the rectangle, and mouse input is
+// translated from the window accordingly. An empty rectangle restores the
+// default of covering the whole window.
+func (d *Driver) SetGUIViewport(rect sdl.Rect) error {
+	if rect.W < 0 || rect.H < 0 {
+		return fmt.Errorf("GUI viewport %v has negative size", rect)
+	}
+	if rect.Empty() {
+		rect = sdl.Rect{}
+	} else if d.logicalW != 0 {
+		return errors.New("GUI viewport cannot be set while a logical size is set")
+	}
+	d.guiViewport = rect
+	d.eventHandler.originX = rect.X
+	d.eventHandler.originY = rect.Y
+	return nil
+}
+
+func (d *Driver) LogicalSize() (width, height int32) {
+	return d.logicalW, d.logicalH
+}
+
+// SetLogicalSize sets a fixed logical size for the renderer, which SDL scales
+// to fit the window while keeping its aspect ratio, letterboxing as needed with
+// the background color (see SetBGColor). SDL also maps mouse input to logical
+// coordinates, so the GUI can be laid out for a single design resolution, e.g.
+// 1280x720, regardless of the window size.
+//
+// A logical size suits a GUI with a fixed design that should scale uniformly
+// with the window, like a game menu. The render scale (see SetRenderScale)
+// suits a GUI that reflows to fill the window, like a tool, since it only
+// enlarges the GUI for high-DPI displays and leaves the layout in window
+// coordinates. The two are mutually exclusive: while a logical size is set,
+// the render scale is not used and cannot be set, and the GUI viewport (see
+// SetGUIViewport) cannot be set either. A size of 0x0 returns to scaling by the
+// render scale. If SetLogicalSize is called before Init, the size is applied
+// by Init.
+func (d *Driver) SetLogicalSize(width, height int32) error {
+	if width < 0 || height < 0 || (width == 0) != (height == 0) {
+		return fmt.Errorf("logical size %dx%d is invalid", width, height)
+	} else if width != 0 && !d.guiViewport.Empty() {
+		return errors.New("logical size cannot be set while a GUI viewport is set")
+	}
+	if d.renderer != nil {
+		if err := d.renderer.SetLogicalSize(width, height); err != nil {
+			return fmt.Errorf("setting renderer logical size: %w", err)
+		}
+	}
+	d.logicalW, d.logicalH = width, height
+	return nil
+}
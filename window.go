@@ -0,0 +1,160 @@
+package nksdl
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// AlwaysOnTop returns true if the window is kept above all other windows.
+func (d *Driver) AlwaysOnTop() bool {
+	return d.window.GetFlags()&sdl.WINDOW_ALWAYS_ON_TOP != 0
+}
+
+// SetAlwaysOnTop sets whether the window is kept above all other windows. To
+// set this when creating the window, use sdl.WINDOW_ALWAYS_ON_TOP in
+// WindowOpts.Flags. Some window managers ignore this request.
+func (d *Driver) SetAlwaysOnTop(onTop bool) {
+	d.window.SetAlwaysOnTop(onTop)
+}
+
+// InputGrabbed returns true if input is grabbed by the window.
+func (d *Driver) InputGrabbed() bool {
+	return d.window.GetGrab()
+}
+
+// SetInputGrabbed sets whether input is grabbed by the window, which confines
+// the mouse cursor to the window. To set this when creating the window, use
+// sdl.WINDOW_INPUT_GRABBED in WindowOpts.Flags. Whether the keyboard is also
+// grabbed, so that system shortcuts like Alt+Tab reach the window instead, is
+// controlled by the SDL_GRAB_KEYBOARD hint, and some platforms do not allow it.
+func (d *Driver) SetInputGrabbed(grabbed bool) {
+	d.window.SetGrab(grabbed)
+}
+
+// WindowOpacity returns the opacity of the window, from 0 (fully transparent)
+// to 1 (fully opaque). It is always 1 on platforms that do not support window
+// opacity.
+func (d *Driver) WindowOpacity() (float32, error) {
+	opacity, err := d.window.GetWindowOpacity()
+	if err != nil {
+		return 0, fmt.Errorf("getting window opacity: %w", err)
+	}
+	return opacity, nil
+}
+
+// SetWindowOpacity sets the opacity of the whole window, including the GUI,
+// from 0 (fully transparent) to 1 (fully opaque), e.g. for a translucent
+// overlay. To set this when creating the window, use WindowOpts.Opacity. Window
+// opacity is supported on Windows, macOS, and X11 with a compositing window
+// manager, but not on Wayland or most mobile platforms, where SetWindowOpacity
+// returns an error.
+func (d *Driver) SetWindowOpacity(opacity float32) error {
+	// x != x means x is NaN
+	if opacity != opacity || opacity < 0 || opacity > 1 {
+		return fmt.Errorf("opacity(%g) is out of bounds", opacity)
+	}
+	if err := d.window.SetWindowOpacity(opacity); err != nil {
+		return fmt.Errorf("setting window opacity to %g: %w", opacity, err)
+	}
+	return nil
+}
+
+// EnterKioskMode puts the window into kiosk mode, e.g. for a dashboard or
+// public terminal: borderless fullscreen on the display it is on (see
+// SetFullscreenOnDisplay), always on top, and with input grabbed so that the
+// mouse cursor is confined to it. Platform caveats of the individual settings
+// apply; in particular, kiosk mode does not prevent the user from switching
+// away by system shortcuts unless the platform allows grabbing the keyboard.
+func (d *Driver) EnterKioskMode() error {
+	displayIndex, err := d.window.GetDisplayIndex()
+	if err != nil {
+		return fmt.Errorf("getting window display index: %w", err)
+	}
+	if err := d.SetFullscreenOnDisplay(displayIndex); err != nil {
+		return err
+	}
+	d.SetAlwaysOnTop(true)
+	d.SetInputGrabbed(true)
+	return nil
+}
+
+// LeaveKioskMode takes the window out of kiosk mode (see EnterKioskMode),
+// returning it to a window which is not always on top and does not grab input.
+func (d *Driver) LeaveKioskMode() error {
+	d.SetInputGrabbed(false)
+	d.SetAlwaysOnTop(false)
+	if err := d.window.SetFullscreen(0); err != nil {
+		return fmt.Errorf("leaving fullscreen: %w", err)
+	}
+	if d.autoScale {
+		if err := d.computeUIScale(); err != nil {
+			return fmt.Errorf("computing UI scale: %w", err)
+		}
+	}
+	return nil
+}
+
+// CloseBehavior determines what happens when the user closes the window, e.g.
+// with its close button. See Driver.SetCloseBehavior.
+type CloseBehavior struct {
+	hide     bool
+	callback func() bool
+}
+
+var (
+	// CloseQuit passes the close on to the EventListener, which by default
+	// quits the application (see QuitOnClose). This is the default.
+	CloseQuit = CloseBehavior{}
+	// CloseHide hides the window instead of closing it, e.g. to keep an
+	// application running in the background. The window can be shown again
+	// with Window().Show().
+	CloseHide = CloseBehavior{hide: true}
+)
+
+// CloseCallback returns a CloseBehavior which calls callback when the user
+// closes the window. If callback returns true, the close is passed on to the
+// EventListener as with CloseQuit; otherwise, the close is vetoed.
+func CloseCallback(callback func() bool) CloseBehavior {
+	return CloseBehavior{callback: callback}
+}
+
+// SetCloseBehavior sets what happens when the user closes the window. Unless
+// the behavior is CloseQuit, the close is intercepted in FrameStart before it
+// reaches the EventListener: when SDL closes its last window, it sends an
+// EventTypeWindowClose event followed by an EventTypeQuit event, and neither
+// is passed to the EventListener if the close is vetoed or the window is
+// hidden, so the close does not become ErrQuit or a pending quit (see
+// SetConfirmQuit). An EventTypeQuit event which does not follow a close, e.g.
+// from a signal or a system-wide quit command, is always passed on.
+//
+// SDL does not close or hide the window itself, so a vetoed close leaves the
+// window open, which window managers accept. A hidden window receives no input,
+// so an application that hides its window must offer another way to show it or
+// to quit, or it keeps running unseen; the frame rate can be lowered in the
+// meantime with SetUnfocusedFPS.
+func (d *Driver) SetCloseBehavior(behavior CloseBehavior) {
+	d.closeBehavior = behavior
+}
+
+// interceptClose applies the close behavior to an event of the given type. It
+// returns true if the event must not be passed to the EventListener.
+func (d *Driver) interceptClose(eventType EventType) bool {
+	switch eventType {
+	case EventTypeWindowClose:
+		d.closeVetoed = false
+		if d.closeBehavior.hide {
+			d.window.Hide()
+			d.closeVetoed = true
+		} else if d.closeBehavior.callback != nil && !d.closeBehavior.callback() {
+			d.closeVetoed = true
+		}
+		return d.closeVetoed
+	case EventTypeQuit:
+		if d.closeVetoed {
+			d.closeVetoed = false
+			return true
+		}
+	}
+	return false
+}